@@ -0,0 +1,270 @@
+package karytree
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+)
+
+// ErrInvariant is returned by CheckInvariants when a search tree is
+// broken, usually by changing its nodes directly.
+var ErrInvariant = errors.New("karytree: search tree invariant violated")
+
+// An AVL is an ordered map like BST, which rotates its nodes after Insert
+// and Delete so that the heights of the subtrees of every node differ by
+// at most one. Its height stays below 1.45*log2(n+2), so its operations
+// are O(log n).
+type AVL[K cmp.Ordered, V any] struct {
+	ordered[K, V]
+}
+
+// NewAVL creates an empty AVL tree.
+func NewAVL[K cmp.Ordered, V any]() *AVL[K, V] {
+	return &AVL[K, V]{}
+}
+
+// Insert sets the value of key. It returns true if key is new, or false
+// if it replaced the value of an existing key.
+func (a *AVL[K, V]) Insert(key K, value V) bool {
+	node, added := a.insert(key, value)
+	if added {
+		a.rebalance(node.parent)
+	}
+	return added
+}
+
+// Delete removes key from the tree. It returns false if key wasn't
+// found.
+func (a *AVL[K, V]) Delete(key K) bool {
+	node := a.removal(key)
+	if node == nil {
+		return false
+	}
+	a.rebalance(a.remove(node))
+	return true
+}
+
+// rebalance fixes the heights from node up to the root, rotating the
+// nodes whose subtrees differ in height by two.
+func (a *AVL[K, V]) rebalance(node *Node[*Entry[K, V]]) {
+	for ; node != nil; node = node.parent {
+		switch balance := entryHeight(node.Left()) - entryHeight(node.Right()); {
+		case balance > 1:
+			if child := node.Left(); entryHeight(child.Left()) < entryHeight(child.Right()) {
+				a.rotate(child, right)
+			}
+			node = a.rotate(node, left)
+		case balance < -1:
+			if child := node.Right(); entryHeight(child.Right()) < entryHeight(child.Left()) {
+				a.rotate(child, left)
+			}
+			node = a.rotate(node, right)
+		default:
+			fixHeight(node)
+		}
+	}
+}
+
+// rotate is ordered.rotate, which also fixes the heights.
+func (a *AVL[K, V]) rotate(node *Node[*Entry[K, V]], side uint) *Node[*Entry[K, V]] {
+	up := a.ordered.rotate(node, side)
+	fixHeight(node)
+	fixHeight(up)
+	return up
+}
+
+// CheckInvariants checks that the tree is a search tree, and that the
+// heights of the subtrees of every node differ by at most one. It
+// returns an error wrapping ErrInvariant for the first violation.
+func (a *AVL[K, V]) CheckInvariants() error {
+	if err := a.ordered.CheckInvariants(); err != nil {
+		return err
+	}
+	_, err := checkAVL(a.root)
+	return err
+}
+
+func checkAVL[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) (int, error) {
+	if node == nil {
+		return 0, nil
+	}
+	l, err := checkAVL(node.Left())
+	if err != nil {
+		return 0, err
+	}
+	r, err := checkAVL(node.Right())
+	if err != nil {
+		return 0, err
+	}
+
+	height := 1 + max(l, r)
+	if node.key.meta.height != height {
+		return 0, fmt.Errorf("%w: node %v has height %d, not %d", ErrInvariant, node.key.Key, node.key.meta.height, height)
+	}
+	if l-r > 1 || r-l > 1 {
+		return 0, fmt.Errorf("%w: node %v has subtrees of heights %d and %d", ErrInvariant, node.key.Key, l, r)
+	}
+	return height, nil
+}
+
+func entryHeight[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) int {
+	if node == nil {
+		return 0
+	}
+	return node.key.meta.height
+}
+
+func fixHeight[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) {
+	node.key.meta.height = 1 + max(entryHeight(node.Left()), entryHeight(node.Right()))
+}
+
+// A RedBlack is an ordered map like BST, which colors its nodes red or
+// black and rotates them after Insert and Delete so that no red node has
+// a red child, and every path from a node down to a missing child has
+// the same number of black nodes. Its height stays below 2*log2(n+1), so
+// its operations are O(log n). It rotates less than AVL, but is less
+// strictly balanced.
+type RedBlack[K cmp.Ordered, V any] struct {
+	ordered[K, V]
+}
+
+// NewRedBlack creates an empty red-black tree.
+func NewRedBlack[K cmp.Ordered, V any]() *RedBlack[K, V] {
+	return &RedBlack[K, V]{}
+}
+
+// Insert sets the value of key. It returns true if key is new, or false
+// if it replaced the value of an existing key.
+func (t *RedBlack[K, V]) Insert(key K, value V) bool {
+	node, added := t.insert(key, value)
+	if !added {
+		return false
+	}
+
+	node.key.meta.red = true
+	for isRed(node.parent) {
+		parent := node.parent
+		grandparent := parent.parent // the root is black, so parent isn't it
+		side := parent.n
+		uncle := grandparent.NthChild(1 - side)
+
+		if isRed(uncle) {
+			parent.key.meta.red = false
+			uncle.key.meta.red = false
+			grandparent.key.meta.red = true
+			node = grandparent
+			continue
+		}
+
+		if node.n != side {
+			node = parent
+			parent = t.rotate(node, 1-side)
+		}
+		parent.key.meta.red = false
+		grandparent.key.meta.red = true
+		t.rotate(grandparent, side)
+	}
+	t.root.key.meta.red = false
+	return true
+}
+
+// Delete removes key from the tree. It returns false if key wasn't
+// found.
+func (t *RedBlack[K, V]) Delete(key K) bool {
+	node := t.removal(key)
+	if node == nil {
+		return false
+	}
+
+	if !isRed(node) {
+		// a black node with one child has a red leaf, which takes its
+		// color, but a black leaf leaves a path one black node short
+		if child := node.firstChild; child != nil {
+			child.key.meta.red = false
+		} else {
+			t.fixBlackLeaf(node)
+		}
+	}
+	t.remove(node)
+	return true
+}
+
+// fixBlackLeaf recolors and rotates the tree so that the black leaf node
+// can be removed. node itself stays a leaf.
+func (t *RedBlack[K, V]) fixBlackLeaf(node *Node[*Entry[K, V]]) {
+	// node is one black node short of its siblings, until a red node can
+	// take the missing color
+	for node != t.root && !isRed(node) {
+		parent := node.parent
+		side := node.n
+		sibling := parent.NthChild(1 - side)
+
+		if isRed(sibling) {
+			sibling.key.meta.red = false
+			parent.key.meta.red = true
+			t.rotate(parent, 1-side)
+			sibling = parent.NthChild(1 - side)
+		}
+
+		if !isRed(sibling.Left()) && !isRed(sibling.Right()) {
+			sibling.key.meta.red = true
+			node = parent
+			continue
+		}
+
+		if !isRed(sibling.NthChild(1 - side)) {
+			sibling.NthChild(side).key.meta.red = false
+			sibling.key.meta.red = true
+			sibling = t.rotate(sibling, side)
+		}
+		sibling.key.meta.red = parent.key.meta.red
+		parent.key.meta.red = false
+		sibling.NthChild(1 - side).key.meta.red = false
+		t.rotate(parent, 1-side)
+		node = t.root
+	}
+	node.key.meta.red = false
+}
+
+// CheckInvariants checks that the tree is a search tree, that its root
+// and the children of its red nodes are black, and that every path from
+// a node down to a missing child has the same number of black nodes. It
+// returns an error wrapping ErrInvariant for the first violation.
+func (t *RedBlack[K, V]) CheckInvariants() error {
+	if err := t.ordered.CheckInvariants(); err != nil {
+		return err
+	}
+	if isRed(t.root) {
+		return fmt.Errorf("%w: the root is red", ErrInvariant)
+	}
+	_, err := checkRedBlack(t.root)
+	return err
+}
+
+func checkRedBlack[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) (int, error) {
+	if node == nil {
+		return 1, nil
+	}
+	if isRed(node) && (isRed(node.Left()) || isRed(node.Right())) {
+		return 0, fmt.Errorf("%w: red node %v has a red child", ErrInvariant, node.key.Key)
+	}
+	l, err := checkRedBlack(node.Left())
+	if err != nil {
+		return 0, err
+	}
+	r, err := checkRedBlack(node.Right())
+	if err != nil {
+		return 0, err
+	}
+	if l != r {
+		return 0, fmt.Errorf("%w: node %v has black heights %d and %d", ErrInvariant, node.key.Key, l, r)
+	}
+	if !isRed(node) {
+		l++
+	}
+	return l, nil
+}
+
+func isRed[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) bool {
+	return node != nil && node.key.meta.red
+}
@@ -0,0 +1,152 @@
+package karytree_test
+
+import (
+	"errors"
+	"math/bits"
+	"testing"
+
+	"github.com/flyingmutant/rapid"
+	"github.com/sevagh/k-ary-tree"
+)
+
+type avlMachine struct {
+	searchTreeMachine
+}
+
+func (m *avlMachine) Init(t *rapid.T) {
+	m.b = karytree.NewAVL[int, int]()
+}
+
+func TestAVLPropertyFuzz(t *testing.T) {
+	rapid.Check(t, rapid.StateMachine(&avlMachine{}))
+}
+
+type redBlackMachine struct {
+	searchTreeMachine
+}
+
+func (m *redBlackMachine) Init(t *rapid.T) {
+	m.b = karytree.NewRedBlack[int, int]()
+}
+
+func TestRedBlackPropertyFuzz(t *testing.T) {
+	rapid.Check(t, rapid.StateMachine(&redBlackMachine{}))
+}
+
+func height[T comparable](root *karytree.Node[T]) int {
+	h := 0
+	for range karytree.Levels(root) {
+		h++
+	}
+	return h
+}
+
+func TestBalancedHeights(t *testing.T) {
+	const n = 1023
+
+	trees := map[string]searchTree{
+		"BST":      karytree.NewBST[int, int](),
+		"AVL":      karytree.NewAVL[int, int](),
+		"RedBlack": karytree.NewRedBlack[int, int](),
+	}
+	// sorted insertions degenerate a plain BST into a linked list
+	maxHeights := map[string]int{
+		"BST":      n,
+		"AVL":      bits.Len(n),
+		"RedBlack": 2 * bits.Len(n),
+	}
+
+	for name, tree := range trees {
+		for i := 0; i < n; i++ {
+			tree.Insert(i, i)
+		}
+		if err := tree.CheckInvariants(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if h := height(tree.Root()); h > maxHeights[name] {
+			t.Errorf("%s: expected a height of at most %d, got %d", name, maxHeights[name], h)
+		}
+
+		for i := 0; i < n; i += 2 {
+			tree.Delete(i)
+		}
+		if err := tree.CheckInvariants(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if key, _, _ := tree.Select(100); key != 201 {
+			t.Errorf("%s: expected Select(100) to be 201, got %d", name, key)
+		}
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	a := karytree.NewAVL[int, string]()
+	for i, key := range []string{"a", "b", "c", "d"} {
+		a.Insert(i, key)
+	}
+
+	// swapping the keys of the root and a leaf breaks the ordering
+	root, leaf := a.Root(), a.Root().Right().Right()
+	rootKey, leafKey := root.Key().Key, leaf.Key().Key
+	root.Key().Key, leaf.Key().Key = leafKey, rootKey
+	if err := a.CheckInvariants(); !errors.Is(err, karytree.ErrInvariant) {
+		t.Errorf("expected an out of order tree to fail the checks, got %v", err)
+	}
+	root.Key().Key, leaf.Key().Key = rootKey, leafKey
+
+	// detaching a leaf breaks the heights and sizes
+	leaf.Detach()
+	if err := a.CheckInvariants(); !errors.Is(err, karytree.ErrInvariant) {
+		t.Errorf("expected an unbalanced tree to fail the checks, got %v", err)
+	}
+
+	r := karytree.NewRedBlack[int, string]()
+	r.Insert(1, "a")
+	r.Insert(2, "b")
+	r.Root().Right().SetKey(&karytree.Entry[int, string]{Key: 2, Value: "b"})
+	if err := r.CheckInvariants(); !errors.Is(err, karytree.ErrInvariant) {
+		t.Errorf("expected a bad size to fail the checks, got %v", err)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// (p (q a b) c) rotates right to (q a (p b c)) and back
+	p, q := karytree.Binary("p"), karytree.Binary("q")
+	a, b, c := karytree.Binary("a"), karytree.Binary("b"), karytree.Binary("c")
+	p.SetLeft(&q)
+	p.SetRight(&c)
+	q.SetLeft(&a)
+	q.SetRight(&b)
+	parent := karytree.Binary("parent")
+	parent.SetRight(&p)
+
+	inorder := func() string {
+		s := ""
+		for node := range karytree.InorderSeq(&parent) {
+			s += node.Key()
+		}
+		return s
+	}
+	before := inorder()
+
+	if up := p.RotateRight(); up != &q {
+		t.Fatalf("expected q to be the new root of the subtree")
+	}
+	if parent.Right() != &q || q.Parent() != &parent || q.Right() != &p || p.Left() != &b || b.Parent() != &p || q.Left() != &a {
+		t.Errorf("unexpected links after rotating right")
+	}
+	if inorder() != before {
+		t.Errorf("expected rotations to keep the inorder sequence %s, got %s", before, inorder())
+	}
+
+	if up := q.RotateLeft(); up != &p {
+		t.Fatalf("expected p to be the new root of the subtree")
+	}
+	if parent.Right() != &p || p.Left() != &q || q.Right() != &b || p.Right() != &c {
+		t.Errorf("unexpected links after rotating back left")
+	}
+
+	if a.RotateLeft() != &a || a.Parent() != &q {
+		t.Errorf("expected rotating a leaf to do nothing")
+	}
+}
@@ -1,13 +1,20 @@
 package karytree
 
+import (
+	"iter"
+)
+
 const (
 	left  = iota
 	right = iota
 )
 
-// Binary creates a binary karytree.Node
+// Binary creates a binary karytree.Node, which only accepts a left
+// and a right child.
+// Like NewNode, it returns a node that must not be copied once it has
+// children.
 func Binary[T comparable](key T) Node[T] {
-	return NewNode(key)
+	return NewKaryNode(key, 2)
 }
 
 // SetLeft sets the left child.
@@ -30,7 +37,42 @@ func (k *Node[T]) Right() *Node[T] {
 	return k.NthChild(right)
 }
 
+// RotateLeft rotates the subtree rooted at k to the left: its right
+// child r takes its place in its parent, k becomes the left child of r,
+// and the left subtree of r becomes the right subtree of k. The inorder
+// sequence of the subtree doesn't change. It returns the new root r of
+// the subtree, or k if it has no right child.
+func (k *Node[T]) RotateLeft() *Node[T] {
+	return k.rotate(right)
+}
+
+// RotateRight is the mirror of RotateLeft: the left child of k takes its
+// place, and k becomes its right child.
+func (k *Node[T]) RotateRight() *Node[T] {
+	return k.rotate(left)
+}
+
+// rotate lifts the child of k on side up in place of k.
+func (k *Node[T]) rotate(side uint) *Node[T] {
+	up := k.NthChild(side)
+	if up == nil {
+		return k
+	}
+
+	parent, n := k.parent, k.n
+	up.Detach()
+	if inner := up.NthChild(1 - side); inner != nil {
+		k.setNthChild(side, inner)
+	}
+	if parent != nil {
+		parent.setNthChild(n, up)
+	}
+	up.setNthChild(1-side, k)
+	return up
+}
+
 // InorderIterative is a channel-based iterative implementation of an preorder traversal.
+// Prefer InorderSeq, or InorderContext which can't leak its goroutine.
 func InorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-chan *Node[T] {
 	nChan := make(chan *Node[T])
 
@@ -66,6 +108,7 @@ func InorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-chan
 }
 
 // PreorderIterative is a channel-based iterative implementation of an preorder traversal.
+// Prefer PreorderSeq, or PreorderContext which can't leak its goroutine.
 func PreorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-chan *Node[T] {
 	nChan := make(chan *Node[T])
 
@@ -106,6 +149,7 @@ func PreorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-chan
 }
 
 // PostorderIterative is a channel-based iterative implementation of an preorder traversal.
+// Prefer PostorderSeq, or PostorderContext which can't leak its goroutine.
 func PostorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-chan *Node[T] {
 	nChan := make(chan *Node[T])
 
@@ -145,6 +189,102 @@ func PostorderIterative[T comparable](root *Node[T], quit <-chan struct{}) <-cha
 	return nChan
 }
 
+// InorderSeq is an iterator-based iterative implementation of an inorder traversal.
+func InorderSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		stack := []*Node[T]{}
+		curr := root
+
+		for {
+			for curr != nil {
+				stack = append(stack, curr)
+				curr = curr.Left()
+			}
+
+			if len(stack) == 0 {
+				return
+			}
+
+			stack, curr = stack[:len(stack)-1], stack[len(stack)-1]
+
+			if !yield(curr) {
+				return
+			}
+
+			curr = curr.Right()
+		}
+	}
+}
+
+// PreorderSeq is an iterator-based iterative implementation of a preorder traversal.
+func PreorderSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		if root == nil {
+			return
+		}
+
+		stack := []*Node[T]{}
+		curr := root
+
+		for {
+			if !yield(curr) {
+				return
+			}
+
+			left := curr.Left()
+			right := curr.Right()
+			if left != nil {
+				if right != nil {
+					stack = append(stack, right)
+				}
+				curr = left
+				continue
+			}
+			if right != nil {
+				curr = right
+				continue
+			}
+
+			if len(stack) == 0 {
+				return
+			}
+
+			stack, curr = stack[:len(stack)-1], stack[len(stack)-1]
+		}
+	}
+}
+
+// PostorderSeq is an iterator-based iterative implementation of a postorder traversal.
+// Unlike PostorderIterative it uses a single stack, so nodes are yielded
+// as soon as both of their subtrees are done.
+func PostorderSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		stack := []*Node[T]{}
+		var lastVisited *Node[T]
+		curr := root
+
+		for curr != nil || len(stack) > 0 {
+			for curr != nil {
+				stack = append(stack, curr)
+				curr = curr.Left()
+			}
+
+			top := stack[len(stack)-1]
+			right := top.Right()
+			if right != nil && right != lastVisited {
+				curr = right
+				continue
+			}
+
+			stack = stack[:len(stack)-1]
+			if !yield(top) {
+				return
+			}
+			lastVisited = top
+		}
+	}
+}
+
 // InorderRecursive is a recursive inorder traversal with visitors
 func InorderRecursive[T comparable](root *Node[T], f func(*Node[T])) {
 	inorder(root, f)
@@ -1,6 +1,8 @@
 package karytree_test
 
 import (
+	"errors"
+	"iter"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -952,3 +954,122 @@ func TestInorderRecursive2(t *testing.T) {
 		t.Errorf("got wrong amount of nodes: %+v, expected 5", i)
 	}
 }
+
+func seqKeys(seq iter.Seq[*karytree.Node[interface{}]]) []string {
+	keys := []string{}
+	for node := range seq {
+		keys = append(keys, node.Key().(string))
+	}
+	return keys
+}
+
+func constructBinaryTreeSeq() *karytree.Node[interface{}] {
+	a := karytree.Binary[interface{}]("a")
+	b := karytree.Binary[interface{}]("b")
+	c := karytree.Binary[interface{}]("c")
+	d := karytree.Binary[interface{}]("d")
+	e := karytree.Binary[interface{}]("e")
+	f := karytree.Binary[interface{}]("f")
+	g := karytree.Binary[interface{}]("g")
+
+	a.SetLeft(&b)
+	a.SetRight(&c)
+	b.SetRight(&d)
+	d.SetLeft(&e)
+	c.SetLeft(&f)
+	c.SetRight(&g)
+
+	/*
+	      a
+	    /   \
+	   b     c
+	    \   / \
+	     d f   g
+	    /
+	   e
+	*/
+
+	return &a
+}
+
+func TestInorderSeq(t *testing.T) {
+	a := constructBinaryTreeSeq()
+
+	got := seqKeys(karytree.InorderSeq(a))
+	expected := []string{"b", "e", "d", "a", "f", "c", "g"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected inorder %+v, got %+v", expected, got)
+	}
+}
+
+func TestPreorderSeq(t *testing.T) {
+	a := constructBinaryTreeSeq()
+
+	got := seqKeys(karytree.PreorderSeq(a))
+	expected := []string{"a", "b", "d", "e", "c", "f", "g"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected preorder %+v, got %+v", expected, got)
+	}
+}
+
+func TestPostorderSeq(t *testing.T) {
+	a := constructBinaryTreeSeq()
+
+	got := seqKeys(karytree.PostorderSeq(a))
+	expected := []string{"e", "d", "b", "f", "g", "c", "a"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected postorder %+v, got %+v", expected, got)
+	}
+}
+
+func TestSeqEarlyBreak(t *testing.T) {
+	a := constructBinaryTreeSeq()
+
+	seqs := map[string]iter.Seq[*karytree.Node[interface{}]]{
+		"inorder":   karytree.InorderSeq(a),
+		"preorder":  karytree.PreorderSeq(a),
+		"postorder": karytree.PostorderSeq(a),
+	}
+
+	for name, seq := range seqs {
+		ctr := 0
+		for range seq {
+			ctr++
+			if ctr == 2 {
+				break
+			}
+		}
+		if ctr != 2 {
+			t.Errorf("%s: expected to stop after 2 nodes, got %d", name, ctr)
+		}
+	}
+}
+
+func TestSeqNil(t *testing.T) {
+	for name, seq := range map[string]iter.Seq[*karytree.Node[interface{}]]{
+		"inorder":   karytree.InorderSeq[interface{}](nil),
+		"preorder":  karytree.PreorderSeq[interface{}](nil),
+		"postorder": karytree.PostorderSeq[interface{}](nil),
+	} {
+		for node := range seq {
+			t.Errorf("%s: expected no nodes from nil root, got %+v", name, node)
+		}
+	}
+}
+
+func TestBinaryRejectsOutOfRange(t *testing.T) {
+	a := karytree.Binary[interface{}]("a")
+	b := karytree.Binary[interface{}]("b")
+
+	if a.K() != 2 {
+		t.Errorf("expected a binary node to have k=2, got %d", a.K())
+	}
+
+	if _, err := a.TrySetNthChild(7, &b); !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for a 7th child, got %v", err)
+	}
+
+	if a.Left() != nil || a.Right() != nil {
+		t.Errorf("out of range child shouldn't have been attached")
+	}
+}
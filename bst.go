@@ -0,0 +1,394 @@
+package karytree
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+)
+
+// An Entry is the key of a node of a BST, AVL or RedBlack tree: a
+// key-value pair, with the bookkeeping of its node.
+type Entry[K cmp.Ordered, V any] struct {
+	Key   K
+	Value V
+	meta  entryMeta
+}
+
+// entryMeta belongs to the position of a node in the tree rather than to
+// its key, so it stays in place when entries are swapped.
+type entryMeta struct {
+	size   int // of the subtree, for Rank and Select
+	height int // of the subtree, for AVL
+	red    bool
+}
+
+// A BST is an ordered map from K to V, kept as a binary search tree of
+// Binary nodes: every key in the left subtree of a node is less than its
+// key, and every key in its right subtree is greater. It isn't balanced,
+// so its operations take time proportional to the height of the tree.
+//
+// Root exposes the nodes, so InorderSeq and the other traversals work on
+// them, but changing them directly can break the ordering.
+type BST[K cmp.Ordered, V any] struct {
+	ordered[K, V]
+}
+
+// ordered holds the operations shared by the search trees, which don't
+// change their shape, and the unbalanced updates that they build on.
+type ordered[K cmp.Ordered, V any] struct {
+	root *Node[*Entry[K, V]]
+}
+
+// NewBST creates an empty binary search tree.
+func NewBST[K cmp.Ordered, V any]() *BST[K, V] {
+	return &BST[K, V]{}
+}
+
+// Root gets the root node of the tree, or nil if it's empty.
+func (b *ordered[K, V]) Root() *Node[*Entry[K, V]] {
+	return b.root
+}
+
+// Len counts the keys in the tree.
+func (b *ordered[K, V]) Len() int {
+	return entrySize(b.root)
+}
+
+// Get gets the value of key, and whether it was found.
+func (b *ordered[K, V]) Get(key K) (V, bool) {
+	if node := b.find(key); node != nil {
+		return node.key.Value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert sets the value of key. It returns true if key is new, or false
+// if it replaced the value of an existing key.
+func (b *BST[K, V]) Insert(key K, value V) bool {
+	_, added := b.insert(key, value)
+	return added
+}
+
+// Delete removes key from the tree. It returns false if key wasn't
+// found.
+func (b *BST[K, V]) Delete(key K) bool {
+	node := b.removal(key)
+	if node == nil {
+		return false
+	}
+	b.remove(node)
+	return true
+}
+
+// insert sets the value of key, adding a leaf if key is new, and returns
+// the node of key.
+func (b *ordered[K, V]) insert(key K, value V) (*Node[*Entry[K, V]], bool) {
+	if b.root == nil {
+		b.root = newEntryNode(key, value)
+		return b.root, true
+	}
+
+	curr := b.root
+	for {
+		c := cmp.Compare(key, curr.key.Key)
+		if c == 0 {
+			curr.key.Value = value
+			return curr, false
+		}
+
+		n := uint(left)
+		if c > 0 {
+			n = right
+		}
+		next := curr.NthChild(n)
+		if next == nil {
+			next = newEntryNode(key, value)
+			curr.SetNthChild(n, next)
+			for node := range next.Ancestors() {
+				node.key.meta.size++
+			}
+			return next, true
+		}
+		curr = next
+	}
+}
+
+// removal finds the node to remove to delete key, which has at most one
+// child, or returns nil if key isn't in the tree. A node with two
+// children swaps entries with its successor, the leftmost node of its
+// right subtree, which is removed instead.
+func (b *ordered[K, V]) removal(key K) *Node[*Entry[K, V]] {
+	node := b.find(key)
+	if node == nil || node.Left() == nil || node.Right() == nil {
+		return node
+	}
+
+	succ := node.Right()
+	for succ.Left() != nil {
+		succ = succ.Left()
+	}
+	entry, succEntry := node.key, succ.key
+	entry.meta, succEntry.meta = succEntry.meta, entry.meta
+	node.SetKey(succEntry)
+	succ.SetKey(entry)
+	return succ
+}
+
+// remove puts the only child of node, if any, in place of node, and
+// returns the parent of node.
+func (b *ordered[K, V]) remove(node *Node[*Entry[K, V]]) *Node[*Entry[K, V]] {
+	parent := node.parent
+	child := node.Left()
+	if child == nil {
+		child = node.Right()
+	}
+
+	switch {
+	case node == b.root:
+		if child != nil {
+			child.Detach()
+		}
+		b.root = child
+	case child == nil:
+		node.Detach()
+	default:
+		node.ReplaceSubtree(child)
+	}
+
+	for curr := parent; curr != nil; curr = curr.parent {
+		curr.key.meta.size--
+	}
+	return parent
+}
+
+// rotate lifts the child of node on side up in place of node, like
+// RotateLeft or RotateRight, and returns it.
+func (b *ordered[K, V]) rotate(node *Node[*Entry[K, V]], side uint) *Node[*Entry[K, V]] {
+	up := node.rotate(side)
+	if node == b.root {
+		b.root = up
+	}
+	node.key.meta.size = 1 + entrySize(node.Left()) + entrySize(node.Right())
+	up.key.meta.size = 1 + entrySize(up.Left()) + entrySize(up.Right())
+	return up
+}
+
+// Min gets the smallest key and its value. It returns false if the tree
+// is empty.
+func (b *ordered[K, V]) Min() (K, V, bool) {
+	curr := b.root
+	for curr != nil && curr.Left() != nil {
+		curr = curr.Left()
+	}
+	return entryOf(curr)
+}
+
+// Max gets the largest key and its value. It returns false if the tree
+// is empty.
+func (b *ordered[K, V]) Max() (K, V, bool) {
+	curr := b.root
+	for curr != nil && curr.Right() != nil {
+		curr = curr.Right()
+	}
+	return entryOf(curr)
+}
+
+// Floor gets the largest key less than or equal to key, and its value.
+// It returns false if there is none.
+func (b *ordered[K, V]) Floor(key K) (K, V, bool) {
+	var found *Node[*Entry[K, V]]
+	for curr := b.root; curr != nil; {
+		switch c := cmp.Compare(key, curr.key.Key); {
+		case c == 0:
+			return entryOf(curr)
+		case c < 0:
+			curr = curr.Left()
+		default:
+			found = curr
+			curr = curr.Right()
+		}
+	}
+	return entryOf(found)
+}
+
+// Ceiling gets the smallest key greater than or equal to key, and its
+// value. It returns false if there is none.
+func (b *ordered[K, V]) Ceiling(key K) (K, V, bool) {
+	var found *Node[*Entry[K, V]]
+	for curr := b.root; curr != nil; {
+		switch c := cmp.Compare(key, curr.key.Key); {
+		case c == 0:
+			return entryOf(curr)
+		case c > 0:
+			curr = curr.Right()
+		default:
+			found = curr
+			curr = curr.Left()
+		}
+	}
+	return entryOf(found)
+}
+
+// Rank counts the keys less than key, whether key is in the tree or not.
+func (b *ordered[K, V]) Rank(key K) int {
+	rank := 0
+	for curr := b.root; curr != nil; {
+		switch c := cmp.Compare(key, curr.key.Key); {
+		case c == 0:
+			return rank + entrySize(curr.Left())
+		case c < 0:
+			curr = curr.Left()
+		default:
+			rank += entrySize(curr.Left()) + 1
+			curr = curr.Right()
+		}
+	}
+	return rank
+}
+
+// Select gets the key of rank i, the (i+1)th smallest, and its value. It
+// returns false if i is out of range.
+func (b *ordered[K, V]) Select(i int) (K, V, bool) {
+	curr := b.root
+	for curr != nil {
+		size := entrySize(curr.Left())
+		switch {
+		case i == size:
+			return entryOf(curr)
+		case i < size:
+			curr = curr.Left()
+		default:
+			i -= size + 1
+			curr = curr.Right()
+		}
+	}
+	return entryOf[K, V](nil)
+}
+
+// All returns an iterator over the keys and values of the tree, in
+// ascending order of keys.
+func (b *ordered[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for node := range InorderSeq(b.root) {
+			if !yield(node.key.Key, node.key.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the keys and values of the tree with
+// lo <= key < hi, in ascending order of keys. It skips the subtrees
+// outside of the range.
+func (b *ordered[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		stack := []*Node[*Entry[K, V]]{}
+		curr := b.root
+
+		for {
+			for curr != nil {
+				if cmp.Less(curr.key.Key, lo) {
+					curr = curr.Right()
+					continue
+				}
+				stack = append(stack, curr)
+				curr = curr.Left()
+			}
+
+			if len(stack) == 0 {
+				return
+			}
+
+			stack, curr = stack[:len(stack)-1], stack[len(stack)-1]
+			if !cmp.Less(curr.key.Key, hi) || !yield(curr.key.Key, curr.key.Value) {
+				return
+			}
+
+			curr = curr.Right()
+		}
+	}
+}
+
+// CheckInvariants checks that the keys of the tree are in order, and
+// that its parent links and subtree sizes are right. It returns an error
+// wrapping ErrInvariant for the first violation.
+func (b *ordered[K, V]) CheckInvariants() error {
+	if b.root != nil && b.root.parent != nil {
+		return fmt.Errorf("%w: the root has a parent", ErrInvariant)
+	}
+	_, _, err := checkOrdered(b.root)
+	return err
+}
+
+// checkOrdered checks the subtree of node, and returns its first and
+// last nodes in order.
+func checkOrdered[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) (first, last *Node[*Entry[K, V]], err error) {
+	if node == nil {
+		return nil, nil, nil
+	}
+
+	first, last = node, node
+	size := 1
+	for side, child := range node.Children() {
+		if side > right || child.parent != node {
+			return nil, nil, fmt.Errorf("%w: node %v has a bad child %d", ErrInvariant, node.key.Key, side)
+		}
+		childFirst, childLast, err := checkOrdered(child)
+		if err != nil {
+			return nil, nil, err
+		}
+		if side == left && !cmp.Less(childLast.key.Key, node.key.Key) ||
+			side == right && !cmp.Less(node.key.Key, childFirst.key.Key) {
+			return nil, nil, fmt.Errorf("%w: node %v is out of order", ErrInvariant, child.key.Key)
+		}
+		if side == left {
+			first = childFirst
+		} else {
+			last = childLast
+		}
+		size += child.key.meta.size
+	}
+	if node.key.meta.size != size {
+		return nil, nil, fmt.Errorf("%w: node %v has size %d, not %d", ErrInvariant, node.key.Key, node.key.meta.size, size)
+	}
+	return first, last, nil
+}
+
+func (b *ordered[K, V]) find(key K) *Node[*Entry[K, V]] {
+	curr := b.root
+	for curr != nil {
+		switch c := cmp.Compare(key, curr.key.Key); {
+		case c == 0:
+			return curr
+		case c < 0:
+			curr = curr.Left()
+		default:
+			curr = curr.Right()
+		}
+	}
+	return nil
+}
+
+func newEntryNode[K cmp.Ordered, V any](key K, value V) *Node[*Entry[K, V]] {
+	node := Binary(&Entry[K, V]{Key: key, Value: value, meta: entryMeta{size: 1, height: 1}})
+	return &node
+}
+
+func entrySize[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) int {
+	if node == nil {
+		return 0
+	}
+	return node.key.meta.size
+}
+
+func entryOf[K cmp.Ordered, V any](node *Node[*Entry[K, V]]) (K, V, bool) {
+	if node == nil {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return node.key.Key, node.key.Value, true
+}
@@ -0,0 +1,211 @@
+package karytree_test
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/flyingmutant/rapid"
+	"github.com/sevagh/k-ary-tree"
+)
+
+// fataler is the part of testing.TB that *rapid.T implements too, so
+// the invariant checkers work in both kinds of tests.
+type fataler interface {
+	Fatalf(format string, args ...interface{})
+}
+
+// searchTree is implemented by BST, AVL and RedBlack.
+type searchTree interface {
+	Root() *karytree.Node[*karytree.Entry[int, int]]
+	Len() int
+	Insert(key, value int) bool
+	Delete(key int) bool
+	Get(key int) (int, bool)
+	Floor(key int) (int, int, bool)
+	Ceiling(key int) (int, int, bool)
+	Rank(key int) int
+	Select(i int) (int, int, bool)
+	Range(lo, hi int) iter.Seq2[int, int]
+	CheckInvariants() error
+}
+
+// checkBST checks the invariants of the tree, and that the ordered
+// operations agree with keys, the sorted model of the tree.
+func checkBST(t fataler, b searchTree, keys []int) {
+	if err := b.CheckInvariants(); err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	got := []int{}
+	for node := range karytree.InorderSeq(b.Root()) {
+		got = append(got, node.Key().Key)
+	}
+	if !slices.Equal(got, keys) {
+		t.Fatalf("expected the inorder keys %v, got %v", keys, got)
+	}
+	if b.Len() != len(keys) {
+		t.Fatalf("expected %d keys, got %d", len(keys), b.Len())
+	}
+
+	for i, key := range keys {
+		if b.Rank(key) != i {
+			t.Fatalf("expected key %d to have rank %d, got %d", key, i, b.Rank(key))
+		}
+		if selected, _, ok := b.Select(i); !ok || selected != key {
+			t.Fatalf("expected Select(%d) to be %d, got %d", i, key, selected)
+		}
+	}
+	if _, _, ok := b.Select(len(keys)); ok {
+		t.Fatalf("expected Select(%d) to be out of range", len(keys))
+	}
+}
+
+// searchTreeMachine checks the operations of a searchTree against a
+// sorted slice. rapid makes a new machine for every test case, so each
+// kind of tree has its own machine type with an Init method.
+type searchTreeMachine struct {
+	b    searchTree
+	keys []int
+}
+
+type bstMachine struct {
+	searchTreeMachine
+}
+
+func (m *bstMachine) Init(t *rapid.T) {
+	m.b = karytree.NewBST[int, int]()
+}
+
+func (m *searchTreeMachine) Insert(t *rapid.T) {
+	key := rapid.IntsRange(-50, 50).Draw(t, "key").(int)
+	i, found := slices.BinarySearch(m.keys, key)
+	if m.b.Insert(key, -key) == found {
+		t.Fatalf("Insert(%d) disagrees on whether the key is new", key)
+	}
+	if !found {
+		m.keys = slices.Insert(m.keys, i, key)
+	}
+}
+
+func (m *searchTreeMachine) Delete(t *rapid.T) {
+	key := rapid.IntsRange(-50, 50).Draw(t, "key").(int)
+	i, found := slices.BinarySearch(m.keys, key)
+	if m.b.Delete(key) != found {
+		t.Fatalf("Delete(%d) disagrees on whether the key exists", key)
+	}
+	if found {
+		m.keys = slices.Delete(m.keys, i, i+1)
+	}
+}
+
+func (m *searchTreeMachine) Search(t *rapid.T) {
+	key := rapid.IntsRange(-60, 60).Draw(t, "key").(int)
+	i, found := slices.BinarySearch(m.keys, key)
+
+	if value, ok := m.b.Get(key); ok != found || (ok && value != -key) {
+		t.Fatalf("Get(%d) = %d, %v", key, value, ok)
+	}
+	if m.b.Rank(key) != i {
+		t.Fatalf("expected Rank(%d) to be %d, got %d", key, i, m.b.Rank(key))
+	}
+
+	floor, _, ok := m.b.Floor(key)
+	if found && (!ok || floor != key) || !found && (ok != (i > 0) || ok && floor != m.keys[i-1]) {
+		t.Fatalf("unexpected Floor(%d) = %d, %v", key, floor, ok)
+	}
+	ceiling, _, ok := m.b.Ceiling(key)
+	if ok != (i < len(m.keys)) || ok && ceiling != m.keys[i] {
+		t.Fatalf("unexpected Ceiling(%d) = %d, %v", key, ceiling, ok)
+	}
+}
+
+func (m *searchTreeMachine) Range(t *rapid.T) {
+	lo := rapid.IntsRange(-60, 60).Draw(t, "lo").(int)
+	hi := rapid.IntsRange(-60, 60).Draw(t, "hi").(int)
+
+	expected := []int{}
+	for _, key := range m.keys {
+		if lo <= key && key < hi {
+			expected = append(expected, key)
+		}
+	}
+	got := []int{}
+	for key, value := range m.b.Range(lo, hi) {
+		if value != -key {
+			t.Fatalf("unexpected value %d for key %d", value, key)
+		}
+		got = append(got, key)
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("expected the range [%d, %d) to be %v, got %v", lo, hi, expected, got)
+	}
+}
+
+func (m *searchTreeMachine) Check(t *rapid.T) {
+	checkBST(t, m.b, m.keys)
+}
+
+func TestBSTPropertyFuzz(t *testing.T) {
+	rapid.Check(t, rapid.StateMachine(&bstMachine{}))
+}
+
+func TestBSTOrdered(t *testing.T) {
+	b := karytree.NewBST[string, int]()
+	if _, _, ok := b.Min(); ok {
+		t.Errorf("expected no Min in an empty tree")
+	}
+
+	for i, key := range []string{"m", "c", "x", "a", "f", "p", "z"} {
+		b.Insert(key, i)
+	}
+	if b.Insert("f", 10) {
+		t.Errorf("expected Insert to replace the value of an existing key")
+	}
+	if value, ok := b.Get("f"); !ok || value != 10 {
+		t.Errorf("expected f to be 10, got %d", value)
+	}
+
+	if key, _, _ := b.Min(); key != "a" {
+		t.Errorf("expected Min to be a, got %s", key)
+	}
+	if key, _, _ := b.Max(); key != "z" {
+		t.Errorf("expected Max to be z, got %s", key)
+	}
+	if key, _, _ := b.Floor("o"); key != "m" {
+		t.Errorf("expected Floor(o) to be m, got %s", key)
+	}
+	if key, _, _ := b.Ceiling("o"); key != "p" {
+		t.Errorf("expected Ceiling(o) to be p, got %s", key)
+	}
+	if _, _, ok := b.Ceiling("zz"); ok {
+		t.Errorf("expected no Ceiling above the largest key")
+	}
+
+	got := []string{}
+	for key := range b.Range("c", "p") {
+		got = append(got, key)
+	}
+	if !slices.Equal(got, []string{"c", "f", "m"}) {
+		t.Errorf("unexpected range [c, p): %v", got)
+	}
+	got = got[:0]
+	for key := range b.All() {
+		got = append(got, key)
+		if key == "f" {
+			break
+		}
+	}
+	if !slices.Equal(got, []string{"a", "c", "f"}) {
+		t.Errorf("expected All to stop early, got %v", got)
+	}
+
+	// the root has two children, so it takes the entry of its successor
+	b.Delete("m")
+	if b.Root().Key().Key != "p" || b.Len() != 6 {
+		t.Errorf("expected p to replace the deleted root")
+	}
+	if b.Root().Right().Key().Key != "x" || b.Root().Right().Left() != nil {
+		t.Errorf("expected the successor to be removed from the right subtree")
+	}
+}
@@ -0,0 +1,494 @@
+package karytree
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// A Page is the key of a node of a BTree: its sorted keys, and their
+// values. An internal node with n keys has n+1 children, and the keys
+// of its child i are between Keys[i-1] and Keys[i].
+type Page[K cmp.Ordered, V any] struct {
+	Keys   []K
+	Values []V
+}
+
+// A BTree is an ordered map from K to V, kept as a B-tree of order m:
+// every node has at most m children, and every node but the root at
+// least ceil(m/2) of them, or the same numbers of keys minus one for the
+// leaves. All the leaves are at the same depth, so the height of the
+// tree is O(log_m n), and larger orders make it shallower.
+//
+// Its nodes have the ChildArray layout, with m+1 child indices so that
+// they can overflow before they split. Root exposes them, so BFSSeq and
+// the other traversals work on them, but changing them directly can
+// break the tree.
+type BTree[K cmp.Ordered, V any] struct {
+	order uint
+	root  *Node[*Page[K, V]]
+	len   int
+}
+
+// NewBTree creates an empty B-tree of the given order. It panics if
+// order is less than 3.
+func NewBTree[K cmp.Ordered, V any](order uint) *BTree[K, V] {
+	if order < 3 {
+		panic("karytree: a B-tree needs an order of at least 3")
+	}
+	return &BTree[K, V]{order: order}
+}
+
+// LoadBTree creates a B-tree of the given order from keys in strictly
+// ascending order, with their values. It builds the tree level by level,
+// without splitting any node, and spreads the keys evenly across the
+// nodes of each level. It returns an error wrapping ErrInvariant if the
+// keys are out of order.
+func LoadBTree[K cmp.Ordered, V any](order uint, sorted iter.Seq2[K, V]) (*BTree[K, V], error) {
+	b := NewBTree[K, V](order)
+
+	keys, values := []K{}, []V{}
+	for key, value := range sorted {
+		if len(keys) > 0 && !cmp.Less(keys[len(keys)-1], key) {
+			return nil, fmt.Errorf("%w: key %v doesn't follow %v", ErrInvariant, key, keys[len(keys)-1])
+		}
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	b.len = len(keys)
+	if len(keys) == 0 {
+		return b, nil
+	}
+
+	// the leaves take all the keys but the separators between them, and
+	// each level above groups the nodes below it, taking the separators
+	// between its groups up
+	m := int(order)
+	nodes := []*Node[*Page[K, V]]{}
+	sepKeys, sepValues := []K{}, []V{}
+	leaves := (len(keys) + m) / m
+	pos := 0
+	for i, size := range spread(len(keys)-leaves+1, leaves) {
+		nodes = append(nodes, b.newPage(slices.Clone(keys[pos:pos+size]), slices.Clone(values[pos:pos+size])))
+		pos += size
+		if i < leaves-1 {
+			sepKeys = append(sepKeys, keys[pos])
+			sepValues = append(sepValues, values[pos])
+			pos++
+		}
+	}
+
+	for len(nodes) > 1 {
+		parents := []*Node[*Page[K, V]]{}
+		upKeys, upValues := []K{}, []V{}
+		groups := (len(nodes) + m - 1) / m
+		pos := 0
+		for i, size := range spread(len(nodes), groups) {
+			parent := b.newPage(slices.Clone(sepKeys[pos:pos+size-1]), slices.Clone(sepValues[pos:pos+size-1]))
+			for n, child := range nodes[pos : pos+size] {
+				parent.SetNthChild(uint(n), child)
+			}
+			parents = append(parents, parent)
+			pos += size
+			if i < groups-1 {
+				upKeys = append(upKeys, sepKeys[pos-1])
+				upValues = append(upValues, sepValues[pos-1])
+			}
+		}
+		nodes, sepKeys, sepValues = parents, upKeys, upValues
+	}
+	b.root = nodes[0]
+	return b, nil
+}
+
+// spread splits total into groups sizes that differ by at most one.
+func spread(total, groups int) []int {
+	sizes := make([]int, groups)
+	for i := range sizes {
+		sizes[i] = total / groups
+		if i < total%groups {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
+
+// Order gets the maximum number of children of a node.
+func (b *BTree[K, V]) Order() uint {
+	return b.order
+}
+
+// Root gets the root node of the tree, or nil if it's empty.
+func (b *BTree[K, V]) Root() *Node[*Page[K, V]] {
+	return b.root
+}
+
+// Len counts the keys in the tree.
+func (b *BTree[K, V]) Len() int {
+	return b.len
+}
+
+// Get gets the value of key, and whether it was found.
+func (b *BTree[K, V]) Get(key K) (V, bool) {
+	if node, i := b.find(key); node != nil {
+		return node.key.Values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// Insert sets the value of key. It returns true if key is new, or false
+// if it replaced the value of an existing key.
+func (b *BTree[K, V]) Insert(key K, value V) bool {
+	if b.root == nil {
+		b.root = b.newPage([]K{key}, []V{value})
+		b.len++
+		return true
+	}
+
+	node := b.root
+	for {
+		i, found := slices.BinarySearch(node.key.Keys, key)
+		if found {
+			node.key.Values[i] = value
+			return false
+		}
+		child := node.NthChild(uint(i))
+		if child == nil {
+			node.key.Keys = slices.Insert(node.key.Keys, i, key)
+			node.key.Values = slices.Insert(node.key.Values, i, value)
+			break
+		}
+		node = child
+	}
+	b.len++
+
+	// a full node splits around its median key, which moves up to its
+	// parent, which may be full in turn
+	for len(node.key.Keys) == int(b.order) {
+		page := node.key
+		mid := len(page.Keys) / 2
+		right := b.newPage(slices.Clone(page.Keys[mid+1:]), slices.Clone(page.Values[mid+1:]))
+		for n := mid + 1; n <= len(page.Keys); n++ {
+			if child := node.RemoveNthChild(uint(n)); child != nil {
+				right.SetNthChild(uint(n-mid-1), child)
+			}
+		}
+		median, medianValue := page.Keys[mid], page.Values[mid]
+		clear(page.Keys[mid:])
+		clear(page.Values[mid:])
+		page.Keys, page.Values = page.Keys[:mid], page.Values[:mid]
+
+		parent := node.parent
+		if parent == nil {
+			b.root = b.newPage([]K{median}, []V{medianValue})
+			b.root.SetNthChild(0, node)
+			b.root.SetNthChild(1, right)
+			break
+		}
+		i := int(node.n)
+		parent.key.Keys = slices.Insert(parent.key.Keys, i, median)
+		parent.key.Values = slices.Insert(parent.key.Values, i, medianValue)
+		insertChild(parent, i+1, right)
+		node = parent
+	}
+	return true
+}
+
+// Delete removes key from the tree. It returns false if key wasn't
+// found.
+func (b *BTree[K, V]) Delete(key K) bool {
+	node, i := b.find(key)
+	if node == nil {
+		return false
+	}
+
+	// a key of an internal node is replaced by its predecessor, the last
+	// key of the rightmost leaf of its left subtree
+	if node.firstChild != nil {
+		leaf := node.NthChild(uint(i))
+		for leaf.firstChild != nil {
+			leaf = leaf.NthChild(uint(len(leaf.key.Keys)))
+		}
+		last := len(leaf.key.Keys) - 1
+		node.key.Keys[i], node.key.Values[i] = leaf.key.Keys[last], leaf.key.Values[last]
+		node, i = leaf, last
+	}
+	node.key.Keys = slices.Delete(node.key.Keys, i, i+1)
+	node.key.Values = slices.Delete(node.key.Values, i, i+1)
+	b.len--
+
+	// a node with too few keys borrows one from a sibling through their
+	// parent, or merges with it, which takes a key from the parent
+	minKeys := int(b.order+1)/2 - 1
+	for node != b.root && len(node.key.Keys) < minKeys {
+		parent := node.parent
+		i := int(node.n)
+		var left, right *Node[*Page[K, V]]
+		if i > 0 {
+			left = parent.NthChild(uint(i - 1))
+		}
+		if i < len(parent.key.Keys) {
+			right = parent.NthChild(uint(i + 1))
+		}
+
+		switch {
+		case left != nil && len(left.key.Keys) > minKeys:
+			borrowLeft(parent, i)
+			return true
+		case right != nil && len(right.key.Keys) > minKeys:
+			borrowRight(parent, i)
+			return true
+		case left != nil:
+			merge(parent, i-1)
+		default:
+			merge(parent, i)
+		}
+		node = parent
+	}
+
+	if len(b.root.key.Keys) == 0 {
+		b.root = b.root.firstChild
+		if b.root != nil {
+			b.root.Detach()
+		}
+	}
+	return true
+}
+
+// borrowLeft moves the last key of child i-1 of parent up to parent, and
+// the key of parent between them down to child i.
+func borrowLeft[K cmp.Ordered, V any](parent *Node[*Page[K, V]], i int) {
+	left, node := parent.NthChild(uint(i-1)), parent.NthChild(uint(i))
+	last := len(left.key.Keys) - 1
+
+	node.key.Keys = slices.Insert(node.key.Keys, 0, parent.key.Keys[i-1])
+	node.key.Values = slices.Insert(node.key.Values, 0, parent.key.Values[i-1])
+	parent.key.Keys[i-1], parent.key.Values[i-1] = left.key.Keys[last], left.key.Values[last]
+	left.key.Keys, left.key.Values = left.key.Keys[:last], left.key.Values[:last]
+
+	if child := left.RemoveNthChild(uint(last + 1)); child != nil {
+		insertChild(node, 0, child)
+	}
+}
+
+// borrowRight is the mirror of borrowLeft, from child i+1 of parent.
+func borrowRight[K cmp.Ordered, V any](parent *Node[*Page[K, V]], i int) {
+	node, right := parent.NthChild(uint(i)), parent.NthChild(uint(i+1))
+
+	node.key.Keys = append(node.key.Keys, parent.key.Keys[i])
+	node.key.Values = append(node.key.Values, parent.key.Values[i])
+	parent.key.Keys[i], parent.key.Values[i] = right.key.Keys[0], right.key.Values[0]
+	right.key.Keys = slices.Delete(right.key.Keys, 0, 1)
+	right.key.Values = slices.Delete(right.key.Values, 0, 1)
+
+	if child := removeChild(right, 0); child != nil {
+		node.SetNthChild(uint(len(node.key.Keys)), child)
+	}
+}
+
+// merge moves key i of parent and the keys and children of child i+1
+// into child i.
+func merge[K cmp.Ordered, V any](parent *Node[*Page[K, V]], i int) {
+	left, right := parent.NthChild(uint(i)), removeChild(parent, i+1)
+
+	offset := uint(len(left.key.Keys) + 1)
+	left.key.Keys = append(append(left.key.Keys, parent.key.Keys[i]), right.key.Keys...)
+	left.key.Values = append(append(left.key.Values, parent.key.Values[i]), right.key.Values...)
+	parent.key.Keys = slices.Delete(parent.key.Keys, i, i+1)
+	parent.key.Values = slices.Delete(parent.key.Values, i, i+1)
+
+	for child := right.firstChild; child != nil; child = right.firstChild {
+		left.SetNthChild(offset+child.n, child)
+	}
+}
+
+// insertChild puts child at index i of node, shifting the children from
+// index i on up by one.
+func insertChild[T comparable](node *Node[T], i int, child *Node[T]) {
+	last := -1
+	for n := range node.Children() {
+		last = int(n)
+	}
+	for n := last; n >= i; n-- {
+		node.MoveChild(uint(n), uint(n+1))
+	}
+	node.SetNthChild(uint(i), child)
+}
+
+// removeChild removes the child at index i of node, shifting the
+// children after it down by one, and returns it.
+func removeChild[T comparable](node *Node[T], i int) *Node[T] {
+	child := node.RemoveNthChild(uint(i))
+	for n := i + 1; node.NthChild(uint(n)) != nil; n++ {
+		node.MoveChild(uint(n), uint(n-1))
+	}
+	return child
+}
+
+// Min gets the smallest key and its value. It returns false if the tree
+// is empty.
+func (b *BTree[K, V]) Min() (K, V, bool) {
+	node := b.root
+	for node != nil && node.firstChild != nil {
+		node = node.firstChild
+	}
+	return pageEntry(node, 0)
+}
+
+// Max gets the largest key and its value. It returns false if the tree
+// is empty.
+func (b *BTree[K, V]) Max() (K, V, bool) {
+	node := b.root
+	for node != nil && node.firstChild != nil {
+		node = node.NthChild(uint(len(node.key.Keys)))
+	}
+	if node == nil {
+		return pageEntry(node, 0)
+	}
+	return pageEntry(node, len(node.key.Keys)-1)
+}
+
+// All returns an iterator over the keys and values of the tree, in
+// ascending order of keys.
+func (b *BTree[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		b.scan(b.root, nil, nil, yield)
+	}
+}
+
+// Range returns an iterator over the keys and values of the tree with
+// lo <= key < hi, in ascending order of keys. It skips the subtrees
+// outside of the range.
+func (b *BTree[K, V]) Range(lo, hi K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		b.scan(b.root, &lo, &hi, yield)
+	}
+}
+
+// scan yields the keys of the subtree of node from lo on, until one
+// reaches hi, if they aren't nil. It returns false once it's done.
+func (b *BTree[K, V]) scan(node *Node[*Page[K, V]], lo, hi *K, yield func(K, V) bool) bool {
+	if node == nil {
+		return true
+	}
+
+	page := node.key
+	i := 0
+	if lo != nil {
+		i, _ = slices.BinarySearch(page.Keys, *lo)
+	}
+	for ; i <= len(page.Keys); i++ {
+		if !b.scan(node.NthChild(uint(i)), lo, hi, yield) {
+			return false
+		}
+		if i == len(page.Keys) {
+			break
+		}
+		if hi != nil && !cmp.Less(page.Keys[i], *hi) || !yield(page.Keys[i], page.Values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckInvariants checks that the keys of the tree are in order, that
+// its leaves are all at the same depth, that its nodes have between
+// ceil(m/2) and m children, and that their children have contiguous
+// indices. It returns an error wrapping ErrInvariant for the first
+// violation.
+func (b *BTree[K, V]) CheckInvariants() error {
+	if b.root == nil {
+		if b.len != 0 {
+			return fmt.Errorf("%w: an empty tree has a length of %d", ErrInvariant, b.len)
+		}
+		return nil
+	}
+	if b.root.parent != nil {
+		return fmt.Errorf("%w: the root has a parent", ErrInvariant)
+	}
+
+	count, leafDepth := 0, -1
+	var check func(node *Node[*Page[K, V]], depth int, lo, hi *K) error
+	check = func(node *Node[*Page[K, V]], depth int, lo, hi *K) error {
+		keys := node.key.Keys
+		if len(keys) != len(node.key.Values) {
+			return fmt.Errorf("%w: node %v has %d values", ErrInvariant, keys, len(node.key.Values))
+		}
+		if len(keys) >= int(b.order) || node != b.root && len(keys) < int(b.order+1)/2-1 || len(keys) == 0 {
+			return fmt.Errorf("%w: node %v has %d keys", ErrInvariant, keys, len(keys))
+		}
+		for i, key := range keys {
+			if i > 0 && !cmp.Less(keys[i-1], key) || lo != nil && !cmp.Less(*lo, key) || hi != nil && !cmp.Less(key, *hi) {
+				return fmt.Errorf("%w: node %v is out of order", ErrInvariant, keys)
+			}
+		}
+		count += len(keys)
+
+		if node.firstChild == nil {
+			if leafDepth >= 0 && depth != leafDepth {
+				return fmt.Errorf("%w: leaf %v is at depth %d, not %d", ErrInvariant, keys, depth, leafDepth)
+			}
+			leafDepth = depth
+			return nil
+		}
+
+		if node.NumChildren() != len(keys)+1 {
+			return fmt.Errorf("%w: node %v has %d children", ErrInvariant, keys, node.NumChildren())
+		}
+		for i := 0; i <= len(keys); i++ {
+			child := node.NthChild(uint(i))
+			if child == nil || child.parent != node {
+				return fmt.Errorf("%w: node %v has a bad child %d", ErrInvariant, keys, i)
+			}
+			childLo, childHi := lo, hi
+			if i > 0 {
+				childLo = &keys[i-1]
+			}
+			if i < len(keys) {
+				childHi = &keys[i]
+			}
+			if err := check(child, depth+1, childLo, childHi); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := check(b.root, 0, nil, nil); err != nil {
+		return err
+	}
+	if count != b.len {
+		return fmt.Errorf("%w: the tree has %d keys, not %d", ErrInvariant, count, b.len)
+	}
+	return nil
+}
+
+func (b *BTree[K, V]) find(key K) (*Node[*Page[K, V]], int) {
+	node := b.root
+	for node != nil {
+		i, found := slices.BinarySearch(node.key.Keys, key)
+		if found {
+			return node, i
+		}
+		node = node.NthChild(uint(i))
+	}
+	return nil, 0
+}
+
+func (b *BTree[K, V]) newPage(keys []K, values []V) *Node[*Page[K, V]] {
+	node := NewDenseNode(&Page[K, V]{Keys: keys, Values: values}, b.order+1)
+	return &node
+}
+
+func pageEntry[K cmp.Ordered, V any](node *Node[*Page[K, V]], i int) (K, V, bool) {
+	if node == nil {
+		var (
+			key   K
+			value V
+		)
+		return key, value, false
+	}
+	return node.key.Keys[i], node.key.Values[i], true
+}
@@ -0,0 +1,180 @@
+package karytree_test
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/flyingmutant/rapid"
+	"github.com/sevagh/k-ary-tree"
+)
+
+type btreeMachine struct {
+	b    *karytree.BTree[int, int]
+	keys []int
+}
+
+func (m *btreeMachine) Init(t *rapid.T) {
+	order := rapid.UintsRange(3, 6).Draw(t, "order").(uint)
+	m.b = karytree.NewBTree[int, int](order)
+}
+
+func (m *btreeMachine) Insert(t *rapid.T) {
+	key := rapid.IntsRange(-50, 50).Draw(t, "key").(int)
+	i, found := slices.BinarySearch(m.keys, key)
+	if m.b.Insert(key, -key) == found {
+		t.Fatalf("Insert(%d) disagrees on whether the key is new", key)
+	}
+	if !found {
+		m.keys = slices.Insert(m.keys, i, key)
+	}
+}
+
+func (m *btreeMachine) Delete(t *rapid.T) {
+	key := rapid.IntsRange(-50, 50).Draw(t, "key").(int)
+	i, found := slices.BinarySearch(m.keys, key)
+	if m.b.Delete(key) != found {
+		t.Fatalf("Delete(%d) disagrees on whether the key exists", key)
+	}
+	if found {
+		m.keys = slices.Delete(m.keys, i, i+1)
+	}
+}
+
+func (m *btreeMachine) Get(t *rapid.T) {
+	key := rapid.IntsRange(-60, 60).Draw(t, "key").(int)
+	_, found := slices.BinarySearch(m.keys, key)
+	if value, ok := m.b.Get(key); ok != found || (ok && value != -key) {
+		t.Fatalf("Get(%d) = %d, %v", key, value, ok)
+	}
+}
+
+func (m *btreeMachine) Range(t *rapid.T) {
+	lo := rapid.IntsRange(-60, 60).Draw(t, "lo").(int)
+	hi := rapid.IntsRange(-60, 60).Draw(t, "hi").(int)
+
+	expected := []int{}
+	for _, key := range m.keys {
+		if lo <= key && key < hi {
+			expected = append(expected, key)
+		}
+	}
+	got := []int{}
+	for key := range m.b.Range(lo, hi) {
+		got = append(got, key)
+	}
+	if !slices.Equal(got, expected) {
+		t.Fatalf("expected the range [%d, %d) to be %v, got %v", lo, hi, expected, got)
+	}
+}
+
+func (m *btreeMachine) Check(t *rapid.T) {
+	if err := m.b.CheckInvariants(); err != nil {
+		t.Fatalf("%v", err)
+	}
+	got := []int{}
+	for key := range m.b.All() {
+		got = append(got, key)
+	}
+	if !slices.Equal(got, m.keys) {
+		t.Fatalf("expected the keys %v, got %v", m.keys, got)
+	}
+
+	key, _, ok := m.b.Min()
+	if ok != (len(m.keys) > 0) || ok && key != m.keys[0] {
+		t.Fatalf("unexpected Min %d, %v", key, ok)
+	}
+	key, _, ok = m.b.Max()
+	if ok != (len(m.keys) > 0) || ok && key != m.keys[len(m.keys)-1] {
+		t.Fatalf("unexpected Max %d, %v", key, ok)
+	}
+}
+
+func TestBTreePropertyFuzz(t *testing.T) {
+	rapid.Check(t, rapid.StateMachine(&btreeMachine{}))
+}
+
+func TestLoadBTree(t *testing.T) {
+	for order := uint(3); order <= 9; order++ {
+		for n := 0; n <= 300; n += 7 {
+			values := map[int]string{}
+			for i := range n {
+				values[2*i] = string(rune('a' + i%26))
+			}
+			b, err := karytree.LoadBTree(order, func(yield func(int, string) bool) {
+				for _, key := range slices.Sorted(maps.Keys(values)) {
+					if !yield(key, values[key]) {
+						return
+					}
+				}
+			})
+			if err != nil {
+				t.Fatalf("order=%d, n=%d: %v", order, n, err)
+			}
+			if err := b.CheckInvariants(); err != nil {
+				t.Fatalf("order=%d, n=%d: %v", order, n, err)
+			}
+			if b.Len() != n {
+				t.Errorf("order=%d, n=%d: expected %d keys, got %d", order, n, n, b.Len())
+			}
+			for key, value := range b.All() {
+				if values[key] != value {
+					t.Fatalf("order=%d, n=%d: unexpected value %s for key %d", order, n, value, key)
+				}
+			}
+
+			// a loaded tree keeps working like an inserted one
+			for i := range n {
+				b.Insert(2*i+1, "odd")
+				if i%3 == 0 {
+					b.Delete(2 * i)
+				}
+			}
+			if err := b.CheckInvariants(); err != nil {
+				t.Fatalf("order=%d, n=%d: after updates: %v", order, n, err)
+			}
+		}
+	}
+
+	_, err := karytree.LoadBTree(4, func(yield func(int, int) bool) {
+		_ = yield(1, 1) && yield(3, 3) && yield(2, 2)
+	})
+	if !errors.Is(err, karytree.ErrInvariant) {
+		t.Errorf("expected unsorted keys to be rejected, got %v", err)
+	}
+}
+
+func TestBTreeStructure(t *testing.T) {
+	b := karytree.NewBTree[int, int](3)
+	for i := range 7 {
+		b.Insert(i, i)
+	}
+
+	// a 2-3 tree of 7 sequential keys is complete
+	levels := [][]string{}
+	for _, level := range karytree.Levels(b.Root()) {
+		keys := []string{}
+		for _, node := range level {
+			keys = append(keys, string(rune('0'+len(node.Key().Keys))))
+		}
+		levels = append(levels, keys)
+	}
+	if len(levels) != 3 || len(levels[2]) != 4 || b.Root().Layout() != karytree.ChildArray {
+		t.Errorf("unexpected shape %v", levels)
+	}
+
+	for i := range 7 {
+		b.Delete(i)
+	}
+	if b.Root() != nil || b.Len() != 0 {
+		t.Errorf("expected an empty tree after deleting every key")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected an order of 2 to panic")
+		}
+	}()
+	karytree.NewBTree[int, int](2)
+}
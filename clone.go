@@ -0,0 +1,64 @@
+package karytree
+
+// Clone makes a deep copy of the tree rooted at root. The copy is a new
+// root, but keeps the child index, arity and layout of root, so it
+// Equals root.
+// Clone of nil is nil.
+func Clone[T comparable](root *Node[T]) *Node[T] {
+	return CloneDepth(root, -1)
+}
+
+// CloneDepth is like Clone, but leaves out the nodes deeper than depth
+// below root, so a depth of 0 only copies root itself. A negative depth
+// copies the whole tree.
+func CloneDepth[T comparable](root *Node[T], depth int) *Node[T] {
+	return transform(root, depth, identity[T], nil)
+}
+
+// Map makes a copy of the tree rooted at root with keys converted by f,
+// keeping the child index of every node, and so its sparse structure.
+func Map[T, U comparable](root *Node[T], f func(T) U) *Node[U] {
+	return transform(root, -1, f, nil)
+}
+
+// Filter makes a copy of the tree rooted at root without the subtrees
+// of the nodes for which keep returns false. The remaining nodes keep
+// their child indices. If keep(root) is false, Filter returns nil.
+func Filter[T comparable](root *Node[T], keep func(*Node[T]) bool) *Node[T] {
+	return transform(root, -1, identity[T], keep)
+}
+
+func identity[T any](key T) T {
+	return key
+}
+
+// transform copies the tree rooted at node down to depth, converting its
+// keys with f and leaving out the subtrees for which keep is false.
+func transform[T, U comparable](node *Node[T], depth int, f func(T) U, keep func(*Node[T]) bool) *Node[U] {
+	if node == nil || (keep != nil && !keep(node)) {
+		return nil
+	}
+
+	ret := NewKaryNode(f(node.key), node.k)
+	ret.n = node.n
+
+	// the children past depth are left out, but the layout is kept
+	var last *Node[U]
+	for child := node.firstChild; child != nil && depth != 0; child = child.nextSibling {
+		c := transform(child, depth-1, f, keep)
+		if c == nil {
+			continue
+		}
+		c.parent = &ret
+		if last == nil {
+			ret.firstChild = c
+		} else {
+			last.nextSibling = c
+		}
+		last = c
+	}
+	if node.dense() != nil {
+		ret.indexChildren()
+	}
+	return &ret
+}
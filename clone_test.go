@@ -0,0 +1,137 @@
+package karytree_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestClone(t *testing.T) {
+	tree := constructTreeSparse(6)
+	tree.SetNthChild(40, boundedSubtree())
+
+	clone := karytree.Clone(tree)
+	if !karytree.Equals(tree, clone) {
+		t.Fatalf("expected the clone to equal the original")
+	}
+	if clone.NthChild(40).K() != 3 {
+		t.Errorf("expected the arity to be copied")
+	}
+
+	for orig, copied := range zipBFS(tree, clone) {
+		if orig == copied {
+			t.Fatalf("expected the clone not to share nodes")
+		}
+		if copied.Parent() != nil && copied.Parent().NthChild(copied.ChildIndex()) != copied {
+			t.Errorf("expected parent links to point into the clone")
+		}
+	}
+
+	clone.NthChild(0).SetKey("changed")
+	if karytree.Equals(tree, clone) {
+		t.Errorf("expected changes to the clone not to affect the original")
+	}
+
+	if karytree.Clone[int](nil) != nil {
+		t.Errorf("expected the clone of nil to be nil")
+	}
+}
+
+func boundedSubtree() *karytree.Node[interface{}] {
+	node := karytree.NewKaryNode[interface{}]("bounded", 3)
+	child := karytree.NewNode[interface{}]("child")
+	node.SetNthChild(2, &child)
+	return &node
+}
+
+// zipBFS pairs up the nodes of two trees of the same shape in BFS order.
+func zipBFS[T comparable](a, b *karytree.Node[T]) map[*karytree.Node[T]]*karytree.Node[T] {
+	pairs := map[*karytree.Node[T]]*karytree.Node[T]{}
+	bs := []*karytree.Node[T]{}
+	for node := range karytree.BFSSeq(b) {
+		bs = append(bs, node)
+	}
+	i := 0
+	for node := range karytree.BFSSeq(a) {
+		pairs[node] = bs[i]
+		i++
+	}
+	return pairs
+}
+
+func TestCloneDepth(t *testing.T) {
+	tree := mustParse(t, "(a (b e f) c [5](d g))")
+
+	for depth, expected := range map[int]string{
+		0:  "a",
+		1:  "(a b c [5]d)",
+		2:  "(a (b e f) c [5](d g))",
+		-1: "(a (b e f) c [5](d g))",
+	} {
+		if got := karytree.CloneDepth(tree, depth).String(); got != expected {
+			t.Errorf("depth %d: expected %s, got %s", depth, expected, got)
+		}
+	}
+	nodes := map[string]*karytree.Node[string]{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		node := karytree.NewDenseNode(key, 8)
+		nodes[key] = &node
+	}
+	dense := nodes["a"]
+	dense.SetNthChild(0, nodes["b"])
+	dense.SetNthChild(1, nodes["c"])
+	dense.SetNthChild(5, nodes["d"])
+	nodes["b"].SetNthChild(0, nodes["e"])
+	nodes["b"].SetNthChild(1, nodes["f"])
+	nodes["d"].SetNthChild(0, nodes["g"])
+	for _, depth := range []int{0, 1} {
+		clone := karytree.CloneDepth(dense, depth)
+		for node := range karytree.BFSSeq(clone) {
+			if node.Layout() != karytree.ChildArray {
+				t.Errorf("depth %d: expected %v to keep the ChildArray layout", depth, node.Key())
+			}
+		}
+		if depth == 1 && clone.NthChild(5).Key() != "d" {
+			t.Errorf("depth 1: expected d at index 5 of the child array, got %v", clone.NthChild(5))
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	tree, err := karytree.ParseSExpr("(1 [3](2 [7]4) 5)", strconv.Atoi)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	mapped := karytree.Map(tree, func(key int) string {
+		return strings.Repeat("x", key)
+	})
+
+	expected := mustParse(t, "(x [3](xx [7]xxxx) xxxxx)")
+	if !karytree.Equals(expected, mapped) {
+		t.Errorf("expected %v, got %v", expected, mapped)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	tree := mustParse(t, "(a (b e f) c [5](d g))")
+
+	filtered := karytree.Filter(tree, func(node *karytree.Node[string]) bool {
+		return node.Key() != "b" && node.Key() != "g"
+	})
+	if filtered.String() != "(a [1]c [5]d)" {
+		t.Errorf("expected (a [1]c [5]d), got %v", filtered)
+	}
+	if tree.String() != "(a (b e f) c [5](d g))" {
+		t.Errorf("expected the original to be unchanged, got %v", tree)
+	}
+
+	none := karytree.Filter(tree, func(node *karytree.Node[string]) bool {
+		return false
+	})
+	if none != nil {
+		t.Errorf("expected nil when the root is filtered out, got %v", none)
+	}
+}
@@ -0,0 +1,262 @@
+package karytree
+
+import (
+	"fmt"
+	"iter"
+)
+
+// A CompleteTree is a complete k-ary tree stored as a flat slice of keys
+// in BFS order, without any links: every level is full, except maybe the
+// last one, which is filled from its first child index. Nodes are
+// addressed by their position i in BFS order, with the root at 0. The
+// parent of node i is at (i-1)/k, and its child n is at k*i+1+n, so
+// NthChild is O(1) for any k.
+//
+// A CompleteTree has the same structure as the Node tree that ToNode
+// builds from it, and its traversals visit the nodes in the same order
+// as their Node counterparts.
+type CompleteTree[T comparable] struct {
+	k    uint
+	keys []T
+}
+
+// NewCompleteTree creates a complete tree of arity k holding keys in BFS
+// order. It panics if k is 0.
+func NewCompleteTree[T comparable](k uint, keys ...T) *CompleteTree[T] {
+	if k == 0 {
+		panic("karytree: a complete tree needs a bounded arity k")
+	}
+	return &CompleteTree[T]{k: k, keys: keys}
+}
+
+// K gets the arity of the tree.
+func (c *CompleteTree[T]) K() uint {
+	return c.k
+}
+
+// Len counts the nodes of the tree.
+func (c *CompleteTree[T]) Len() int {
+	return len(c.keys)
+}
+
+// Key gets the data stored in node i.
+func (c *CompleteTree[T]) Key(i int) T {
+	return c.keys[i]
+}
+
+// SetKey modifies the data in node i.
+func (c *CompleteTree[T]) SetKey(i int, key T) {
+	c.keys[i] = key
+}
+
+// Append adds a node with data key in the next free position, which
+// keeps the tree complete, and returns its position.
+func (c *CompleteTree[T]) Append(key T) int {
+	c.keys = append(c.keys, key)
+	return len(c.keys) - 1
+}
+
+// Truncate removes the nodes from position i on, which keeps the tree
+// complete.
+func (c *CompleteTree[T]) Truncate(i int) {
+	clear(c.keys[i:])
+	c.keys = c.keys[:i]
+}
+
+// Parent gets the position of the parent of node i, or -1 for the root.
+func (c *CompleteTree[T]) Parent(i int) int {
+	if i == 0 {
+		return -1
+	}
+	return (i - 1) / int(c.k)
+}
+
+// ChildIndex gets the index n at which node i is a child of its parent.
+func (c *CompleteTree[T]) ChildIndex(i int) uint {
+	if i == 0 {
+		return 0
+	}
+	return uint(i-1) % c.k
+}
+
+// NthChild gets the position of the Nth child of node i, or -1 if there
+// is none.
+func (c *CompleteTree[T]) NthChild(i int, n uint) int {
+	if n >= c.k {
+		return -1
+	}
+	child := int(c.k)*i + 1 + int(n)
+	if child >= len(c.keys) {
+		return -1
+	}
+	return child
+}
+
+// NumChildren counts the children of node i.
+func (c *CompleteTree[T]) NumChildren(i int) int {
+	first := int(c.k)*i + 1
+	return max(0, min(len(c.keys)-first, int(c.k)))
+}
+
+// Depth is the number of edges between node i and the root.
+func (c *CompleteTree[T]) Depth(i int) int {
+	depth := 0
+	for ; i > 0; i = (i - 1) / int(c.k) {
+		depth++
+	}
+	return depth
+}
+
+// BFS returns an iterator over the positions and keys of the nodes in
+// BFS order, which is the order they are stored in.
+func (c *CompleteTree[T]) BFS() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		for i, key := range c.keys {
+			if !yield(i, key) {
+				return
+			}
+		}
+	}
+}
+
+// Preorder returns an iterator over the positions and keys of the nodes
+// in preorder, like KaryPreorderSeq.
+func (c *CompleteTree[T]) Preorder() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if len(c.keys) > 0 {
+			c.inorder(0, 0, yield)
+		}
+	}
+}
+
+// Postorder returns an iterator over the positions and keys of the
+// nodes in postorder, like KaryPostorderSeq.
+func (c *CompleteTree[T]) Postorder() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if len(c.keys) > 0 {
+			c.inorder(0, c.k, yield)
+		}
+	}
+}
+
+// Inorder returns an iterator over the positions and keys of the nodes
+// in inorder, like KaryInorderSeq: each node is visited after the
+// subtrees of its children with index n < pos.
+func (c *CompleteTree[T]) Inorder(pos uint) iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		if len(c.keys) > 0 {
+			c.inorder(0, pos, yield)
+		}
+	}
+}
+
+// inorder visits node i after its children with index n < pos, and
+// before the rest, so a pos of 0 is a preorder and a pos of k is a
+// postorder. It returns false as soon as yield does.
+func (c *CompleteTree[T]) inorder(i int, pos uint, yield func(int, T) bool) bool {
+	visited := false
+	for n := uint(0); n < c.k; n++ {
+		child := c.NthChild(i, n)
+		if child < 0 {
+			break
+		}
+		if n == pos {
+			if !yield(i, c.keys[i]) {
+				return false
+			}
+			visited = true
+		}
+		if !c.inorder(child, pos, yield) {
+			return false
+		}
+	}
+	if !visited {
+		return yield(i, c.keys[i])
+	}
+	return true
+}
+
+// Equals does a deep comparison of two complete trees, with the same
+// semantics as Equals on their Node forms: they are equal if their nodes
+// have the same keys at the same child indices, even if their arities
+// differ.
+func (c *CompleteTree[T]) Equals(other *CompleteTree[T]) bool {
+	if c == other {
+		return true
+	}
+	if c == nil || other == nil || len(c.keys) != len(other.keys) {
+		return false
+	}
+	for i := range c.keys {
+		if c.keys[i] != other.keys[i] {
+			return false
+		}
+		if c.k != other.k && (c.Parent(i) != other.Parent(i) || c.ChildIndex(i) != other.ChildIndex(i)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToNode builds the linked form of the tree, from nodes of arity k. It
+// returns nil for an empty tree.
+func (c *CompleteTree[T]) ToNode() *Node[T] {
+	if len(c.keys) == 0 {
+		return nil
+	}
+
+	nodes := make([]Node[T], len(c.keys))
+	for i, key := range c.keys {
+		nodes[i] = NewKaryNode(key, c.k)
+		if i > 0 {
+			parent := &nodes[c.Parent(i)]
+			nodes[i].n = c.ChildIndex(i)
+			nodes[i].parent = parent
+			if nodes[i].n == 0 {
+				parent.firstChild = &nodes[i]
+			} else {
+				nodes[i-1].nextSibling = &nodes[i]
+			}
+		}
+	}
+	return &nodes[0]
+}
+
+// CompleteFromNode converts the tree rooted at root to a complete tree
+// of arity k, or of the arity of root if k is 0. It returns an error if
+// the tree isn't complete for that arity: every level but the last must
+// be full, and the last one must be filled from its first child index.
+//
+// A nil root, as returned by ToNode for an empty tree, converts to an
+// empty tree of arity k. Since it has no arity of its own, k can't be 0.
+func CompleteFromNode[T comparable](root *Node[T], k uint) (*CompleteTree[T], error) {
+	if k == 0 && root != nil {
+		k = root.k
+	}
+	if k == 0 {
+		return nil, fmt.Errorf("karytree: can't convert an unbounded or nil tree without a k")
+	}
+
+	c := NewCompleteTree[T](k)
+	nodes := []*Node[T]{}
+	for node := range BFSSeq(root) {
+		c.Append(node.key)
+		nodes = append(nodes, node)
+	}
+
+	// in BFS order, the children of each node follow those of the node
+	// before it, so checking their indices and counts checks the shape
+	for i, node := range nodes {
+		n := uint(0)
+		for child := node.firstChild; child != nil; child = child.nextSibling {
+			if child.n != n {
+				return nil, fmt.Errorf("karytree: tree isn't complete for k=%d at child %d of node %d", k, child.n, i)
+			}
+			n++
+		}
+		if int(n) != c.NumChildren(i) {
+			return nil, fmt.Errorf("karytree: tree isn't complete for k=%d at node %d", k, i)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,136 @@
+package karytree_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func completeKeys(size int) []int {
+	keys := make([]int, size)
+	for i := range keys {
+		keys[i] = i
+	}
+	return keys
+}
+
+func TestCompleteTreeIndexing(t *testing.T) {
+	c := karytree.NewCompleteTree(3, completeKeys(8)...)
+
+	if c.NthChild(0, 2) != 3 || c.NthChild(2, 0) != 7 || c.NthChild(2, 1) != -1 || c.NthChild(0, 3) != -1 {
+		t.Errorf("unexpected child positions")
+	}
+	if c.Parent(7) != 2 || c.Parent(3) != 0 || c.Parent(0) != -1 {
+		t.Errorf("unexpected parent positions")
+	}
+	if c.ChildIndex(7) != 0 || c.ChildIndex(6) != 2 {
+		t.Errorf("unexpected child indices")
+	}
+	if c.NumChildren(0) != 3 || c.NumChildren(2) != 1 || c.NumChildren(3) != 0 {
+		t.Errorf("unexpected numbers of children")
+	}
+	if c.Depth(7) != 2 || c.Depth(0) != 0 {
+		t.Errorf("unexpected depths")
+	}
+
+	if c.Append(8) != 8 || c.NthChild(2, 1) != 8 {
+		t.Errorf("expected Append to fill the next child")
+	}
+	c.Truncate(4)
+	if c.Len() != 4 || c.NthChild(1, 0) != -1 {
+		t.Errorf("expected Truncate to remove the last nodes")
+	}
+}
+
+func TestCompleteTreeMatchesNode(t *testing.T) {
+	for _, k := range []uint{1, 2, 3, 8} {
+		for _, size := range []int{1, 2, 5, 13, 40} {
+			c := karytree.NewCompleteTree(k, completeKeys(size)...)
+			node := c.ToNode()
+
+			collect := func(seq func(func(int, int) bool)) []int {
+				keys := []int{}
+				for _, key := range seq {
+					keys = append(keys, key)
+				}
+				return keys
+			}
+			collectNodes := func(seq func(func(*karytree.Node[int]) bool)) []int {
+				keys := []int{}
+				for node := range seq {
+					keys = append(keys, node.Key())
+				}
+				return keys
+			}
+
+			if !slices.Equal(collect(c.BFS()), collectNodes(karytree.BFSSeq(node))) {
+				t.Errorf("k=%d, size=%d: BFS orders differ", k, size)
+			}
+			if !slices.Equal(collect(c.Preorder()), collectNodes(karytree.KaryPreorderSeq(node))) {
+				t.Errorf("k=%d, size=%d: preorders differ", k, size)
+			}
+			if !slices.Equal(collect(c.Postorder()), collectNodes(karytree.KaryPostorderSeq(node))) {
+				t.Errorf("k=%d, size=%d: postorders differ", k, size)
+			}
+			if !slices.Equal(collect(c.Inorder(1)), collectNodes(karytree.KaryInorderSeq(node, 1))) {
+				t.Errorf("k=%d, size=%d: inorders differ", k, size)
+			}
+
+			back, err := karytree.CompleteFromNode(node, 0)
+			if err != nil {
+				t.Fatalf("k=%d, size=%d: conversion failed: %v", k, size, err)
+			}
+			if !back.Equals(c) || back.K() != k {
+				t.Errorf("k=%d, size=%d: expected the round trip to be equal", k, size)
+			}
+			if !karytree.Equals(node, back.ToNode()) {
+				t.Errorf("k=%d, size=%d: expected equal Node forms", k, size)
+			}
+		}
+	}
+}
+
+func TestCompleteTreeEquals(t *testing.T) {
+	a := karytree.NewCompleteTree(2, 0, 1, 2)
+	b := karytree.NewCompleteTree(3, 0, 1, 2)
+	c := karytree.NewCompleteTree(3, 0, 1, 2, 3)
+	d := karytree.NewCompleteTree(2, 0, 1, 2, 3)
+
+	if !a.Equals(b) || !karytree.Equals(a.ToNode(), b.ToNode()) {
+		t.Errorf("expected complete trees of the same shape to be equal")
+	}
+	if c.Equals(d) || karytree.Equals(c.ToNode(), d.ToNode()) {
+		t.Errorf("expected complete trees of different shapes to differ")
+	}
+	if a.Equals(c) {
+		t.Errorf("expected complete trees of different sizes to differ")
+	}
+}
+
+func TestCompleteFromNodeErrors(t *testing.T) {
+	sparse := mustParse(t, "(a [1]b)")
+	if _, err := karytree.CompleteFromNode(sparse, 2); err == nil {
+		t.Errorf("expected an error for a sparse tree")
+	}
+
+	unbalanced := mustParse(t, "(a (b c) d)")
+	if _, err := karytree.CompleteFromNode(unbalanced, 3); err == nil {
+		t.Errorf("expected an error for a tree with a partial inner level")
+	}
+	if _, err := karytree.CompleteFromNode(unbalanced, 2); err != nil {
+		t.Errorf("expected (a (b c) d) to be complete for k=2, got %v", err)
+	}
+
+	if _, err := karytree.CompleteFromNode(unbalanced, 0); err == nil {
+		t.Errorf("expected an error for an unbounded tree without a k")
+	}
+
+	if _, err := karytree.CompleteFromNode[string](nil, 0); err == nil {
+		t.Errorf("expected an error for a nil tree without a k")
+	}
+	empty, err := karytree.CompleteFromNode[string](nil, 3)
+	if err != nil || empty.Len() != 0 || empty.ToNode() != nil {
+		t.Errorf("expected a nil tree to convert to an empty one, got %v, %v", empty, err)
+	}
+}
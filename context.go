@@ -0,0 +1,66 @@
+package karytree
+
+import (
+	"context"
+	"iter"
+)
+
+// BFSContext is a channel-based BFS for tree nodes that stops when ctx
+// is done. The producer goroutine always exits once ctx is done, even
+// if the caller stops reading, so cancelling ctx is the way to quit
+// early.
+//
+// The returned err function reports why the channel was closed: nil if
+// the whole tree was traversed, or ctx.Err() if it was cut short. It
+// must only be called after the channel is closed.
+func BFSContext[T comparable](ctx context.Context, root *Node[T]) (<-chan *Node[T], func() error) {
+	return produce(ctx, BFSSeq(root))
+}
+
+// InorderContext is a channel-based inorder traversal that stops when
+// ctx is done, like BFSContext.
+func InorderContext[T comparable](ctx context.Context, root *Node[T]) (<-chan *Node[T], func() error) {
+	return produce(ctx, InorderSeq(root))
+}
+
+// PreorderContext is a channel-based preorder traversal that stops when
+// ctx is done, like BFSContext.
+func PreorderContext[T comparable](ctx context.Context, root *Node[T]) (<-chan *Node[T], func() error) {
+	return produce(ctx, PreorderSeq(root))
+}
+
+// PostorderContext is a channel-based postorder traversal that stops when
+// ctx is done, like BFSContext.
+func PostorderContext[T comparable](ctx context.Context, root *Node[T]) (<-chan *Node[T], func() error) {
+	return produce(ctx, PostorderSeq(root))
+}
+
+// produce runs seq in a goroutine, sending its nodes on the returned
+// channel until seq is exhausted or ctx is done.
+func produce[T comparable](ctx context.Context, seq iter.Seq[*Node[T]]) (<-chan *Node[T], func() error) {
+	nChan := make(chan *Node[T])
+	var err error
+
+	go func() {
+		// err is written before the close, so reading it after the
+		// channel is drained doesn't race
+		defer close(nChan)
+
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		for node := range seq {
+			select {
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			case nChan <- node:
+			}
+		}
+	}()
+
+	return nChan, func() error {
+		return err
+	}
+}
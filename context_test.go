@@ -0,0 +1,134 @@
+package karytree_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sevagh/k-ary-tree"
+	"go.uber.org/goleak"
+)
+
+func TestBFSContext(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tree := constructTree(4)
+
+	nodes, err := karytree.BFSContext(context.Background(), tree)
+
+	ctr := 0
+	for range nodes {
+		ctr++
+	}
+
+	if ctr != 1+4+16+64 {
+		t.Errorf("expected %d nodes, got %d", 1+4+16+64, ctr)
+	}
+	if err() != nil {
+		t.Errorf("expected a complete traversal to return no error, got %v", err())
+	}
+}
+
+func TestBFSContextCancel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tree := constructTree(4)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	nodes, err := karytree.BFSContext(ctx, tree)
+
+	// read one node, then abandon the channel without draining it
+	<-nodes
+	cancel()
+
+	// the producer must notice the cancellation on its own and close
+	for range nodes {
+	}
+
+	if !errors.Is(err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err())
+	}
+}
+
+func TestBFSContextAbandoned(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tree := constructTree(4)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	nodes, _ := karytree.BFSContext(ctx, tree)
+	<-nodes
+
+	// never read from nodes again; cancelling alone must stop the producer
+	cancel()
+}
+
+func TestBFSContextDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tree := constructTree(4)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	nodes, err := karytree.BFSContext(ctx, tree)
+
+	for range nodes {
+		// slow consumer
+		time.Sleep(time.Millisecond)
+	}
+
+	if !errors.Is(err(), context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", err())
+	}
+}
+
+func TestBFSContextAlreadyDone(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tree := constructTree(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	nodes, err := karytree.BFSContext(ctx, tree)
+	for node := range nodes {
+		t.Errorf("expected no nodes from a cancelled context, got %+v", node)
+	}
+
+	if !errors.Is(err(), context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err())
+	}
+}
+
+func TestBinaryTraversalsContextCancel(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	traversals := map[string]func(context.Context, *karytree.Node[interface{}]) (<-chan *karytree.Node[interface{}], func() error){
+		"inorder":   karytree.InorderContext[interface{}],
+		"preorder":  karytree.PreorderContext[interface{}],
+		"postorder": karytree.PostorderContext[interface{}],
+	}
+
+	for name, traversal := range traversals {
+		a := constructBinaryTreeSeq()
+
+		nodes, err := traversal(context.Background(), a)
+		ctr := 0
+		for range nodes {
+			ctr++
+		}
+		if ctr != 7 || err() != nil {
+			t.Errorf("%s: expected 7 nodes and no error, got %d, %v", name, ctr, err())
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		nodes, err = traversal(ctx, a)
+		<-nodes
+		cancel()
+		for range nodes {
+		}
+		if !errors.Is(err(), context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", name, err())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package karytree
+
+import (
+	"math/bits"
+)
+
+// A Layout is the way a node finds its children.
+type Layout int
+
+const (
+	// SiblingList finds the Nth child by walking the sorted sibling
+	// list, in O(k). It is the default.
+	SiblingList Layout = iota
+	// ChildArray also indexes the children of a bounded node in an array
+	// of size k, with a bitmap of the occupied indices, so NthChild is
+	// O(1). SetNthChild and RemoveNthChild find the previous sibling in
+	// the bitmap instead of walking the sibling list.
+	ChildArray
+)
+
+// dense gets the child array of k, or nil with the SiblingList layout.
+func (k *Node[T]) dense() *denseChildren[T] {
+	if k.ext == nil {
+		return nil
+	}
+	return k.ext.dense
+}
+
+// denseChildren indexes the children of a node with the ChildArray
+// layout. Bit n of occupied is set if slots[n] holds a child.
+type denseChildren[T comparable] struct {
+	slots    []*Node[T]
+	occupied []uint64
+}
+
+// NewDenseNode creates a new node with data key which accepts at most k
+// children, like NewKaryNode, using the ChildArray layout. It panics if
+// k is 0, since only bounded nodes can index their children in an array.
+func NewDenseNode[T comparable](key T, k uint) Node[T] {
+	if k == 0 {
+		panic("karytree: the child array layout needs a bounded arity k")
+	}
+	n := NewKaryNode(key, k)
+	n.indexChildren()
+	return n
+}
+
+// Layout gets the layout of a node.
+func (k *Node[T]) Layout() Layout {
+	if k.dense() != nil {
+		return ChildArray
+	}
+	return SiblingList
+}
+
+// SetLayout switches every node of the tree rooted at root to layout.
+// Unbounded nodes always use the SiblingList layout. The layout doesn't
+// change what a tree contains, so it doesn't matter to Equals, and the
+// traversals work the same on both.
+func SetLayout[T comparable](root *Node[T], layout Layout) {
+	for node := range BFSSeq(root) {
+		if layout == ChildArray && node.k != 0 {
+			node.indexChildren()
+		} else if node.ext != nil {
+			node.ext.dense = nil
+			node.trimExtension()
+		}
+	}
+}
+
+// indexChildren builds the child array of k from its sibling list.
+func (k *Node[T]) indexChildren() {
+	d := &denseChildren[T]{}
+	for child := k.firstChild; child != nil; child = child.nextSibling {
+		d.set(k.k, child.n, child)
+	}
+	k.extension().dense = d
+}
+
+func (d *denseChildren[T]) get(n uint) *Node[T] {
+	if n >= uint(len(d.slots)) {
+		return nil
+	}
+	return d.slots[n]
+}
+
+// set puts child in slot n of a node of arity k. The array is only
+// allocated for the first child, so leaves stay small.
+func (d *denseChildren[T]) set(k, n uint, child *Node[T]) {
+	if d.slots == nil {
+		d.slots = make([]*Node[T], k)
+		d.occupied = make([]uint64, (k+63)/64)
+	}
+	d.slots[n] = child
+	if child != nil {
+		d.occupied[n/64] |= 1 << (n % 64)
+	} else {
+		d.occupied[n/64] &^= 1 << (n % 64)
+	}
+}
+
+// prev finds the child with the highest index below n.
+func (d *denseChildren[T]) prev(n uint) *Node[T] {
+	if d.slots == nil {
+		return nil
+	}
+	w := n / 64
+	mask := d.occupied[w] & (1<<(n%64) - 1)
+	for mask == 0 {
+		if w == 0 {
+			return nil
+		}
+		w--
+		mask = d.occupied[w]
+	}
+	return d.slots[w*64+uint(bits.Len64(mask)-1)]
+}
+
+// setDenseChild is setNthChild for the ChildArray layout.
+func (k *Node[T]) setDenseChild(n uint, other *Node[T]) *Node[T] {
+	d := k.dense()
+	ret := d.get(n)
+	prev := d.prev(n)
+
+	switch {
+	case ret != nil:
+		other.nextSibling = ret.nextSibling
+	case prev != nil:
+		other.nextSibling = prev.nextSibling
+	default:
+		other.nextSibling = k.firstChild
+	}
+	if prev == nil {
+		k.firstChild = other
+	} else {
+		prev.nextSibling = other
+	}
+	d.set(k.k, n, other)
+
+	if ret != nil {
+		ret.unlink()
+	}
+	return ret
+}
+
+// removeDenseChild is RemoveNthChild for the ChildArray layout.
+func (k *Node[T]) removeDenseChild(n uint) *Node[T] {
+	d := k.dense()
+	ret := d.get(n)
+	if ret == nil {
+		return nil
+	}
+
+	if prev := d.prev(n); prev == nil {
+		k.firstChild = ret.nextSibling
+	} else {
+		prev.nextSibling = ret.nextSibling
+	}
+	d.set(k.k, n, nil)
+	ret.unlink()
+	k.invalidate()
+	return ret
+}
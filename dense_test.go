@@ -0,0 +1,164 @@
+package karytree_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestDenseNodeMatchesSiblingList(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+
+	for _, k := range []uint{1, 3, 64, 100} {
+		list := karytree.NewKaryNode(0, k)
+		dense := karytree.NewDenseNode(0, k)
+		if dense.Layout() != karytree.ChildArray || list.Layout() != karytree.SiblingList {
+			t.Fatalf("k=%d: unexpected layouts", k)
+		}
+
+		for i := 1; i < 500; i++ {
+			n := uint(r.Intn(int(k)))
+			var evictedList, evictedDense *karytree.Node[int]
+			switch r.Intn(3) {
+			case 0:
+				evictedList = list.RemoveNthChild(n)
+				evictedDense = dense.RemoveNthChild(n)
+			case 1:
+				a, b := karytree.NewNode(i), karytree.NewNode(i)
+				evictedList = list.SetNthChild(n, &a)
+				evictedDense = dense.SetNthChild(n, &b)
+			default:
+				to := uint(r.Intn(int(k)))
+				evictedList = list.MoveChild(n, to)
+				evictedDense = dense.MoveChild(n, to)
+			}
+
+			if (evictedList == nil) != (evictedDense == nil) ||
+				(evictedList != nil && (evictedList.Key() != evictedDense.Key() || evictedDense.Parent() != nil || evictedDense.NextSibling() != nil)) {
+				t.Fatalf("k=%d, step %d: expected the same eviction", k, i)
+			}
+			if !karytree.Equals(&list, &dense) {
+				t.Fatalf("k=%d, step %d: expected the layouts to be equal", k, i)
+			}
+			for n := uint(0); n < k; n++ {
+				if (list.NthChild(n) == nil) != (dense.NthChild(n) == nil) {
+					t.Fatalf("k=%d, step %d: NthChild(%d) differs", k, i, n)
+				}
+			}
+		}
+	}
+}
+
+func TestDenseMatchesHeapTrees(t *testing.T) {
+	for _, K := range []int{2, 8} {
+		for _, tc := range []struct {
+			name     string
+			expected *karytree.Node[interface{}]
+			keep     [3]func(uint) bool
+		}{
+			{"complete", karyTreeKCompleteHelper(K), completeK},
+			{"sparse", karyTreeKSparseHelper(K), sparseK},
+			{"very sparse", karyTreeKVerySparseHelper(K), verySparseK},
+		} {
+			tree := treeHelper(K, tc.keep, denseNode(K))
+			if !karytree.Equals(tc.expected, tree) {
+				t.Errorf("K=%d: expected the %s dense tree to equal the heap tree", K, tc.name)
+			}
+			for node := range karytree.BFSSeq(tree) {
+				if node.Layout() != karytree.ChildArray {
+					t.Fatalf("K=%d: expected %v to use the ChildArray layout", K, node.Key())
+				}
+			}
+		}
+	}
+}
+
+func TestSetLayout(t *testing.T) {
+	tree := karyTreeKSparseHelper(8)
+	karytree.SetLayout(tree, karytree.ChildArray)
+
+	// unbounded nodes keep the sibling list
+	if tree.Layout() != karytree.SiblingList {
+		t.Errorf("expected an unbounded node to keep the SiblingList layout")
+	}
+
+	a, b, c, d := karytree.NewKaryNode("a", 8), karytree.NewKaryNode("b", 8), karytree.NewKaryNode("c", 8), karytree.NewKaryNode("d", 8)
+	a.SetNthChild(2, &b)
+	a.SetNthChild(5, &d)
+	b.SetNthChild(7, &c)
+	bounded := &a
+	expected := karytree.Clone(bounded)
+	karytree.SetLayout(bounded, karytree.ChildArray)
+	for node := range karytree.BFSSeq(bounded) {
+		if node.Layout() != karytree.ChildArray {
+			t.Fatalf("expected %v to use the ChildArray layout", node.Key())
+		}
+	}
+	if bounded.NthChild(2).NthChild(7).Key() != "c" || bounded.NthChild(3) != nil {
+		t.Errorf("unexpected children in the ChildArray layout")
+	}
+	if !karytree.Equals(bounded, expected) {
+		t.Errorf("expected SetLayout to keep the tree equal")
+	}
+
+	clone := karytree.Clone(bounded)
+	if clone.Layout() != karytree.ChildArray || clone.NthChild(2).NthChild(7).Key() != "c" {
+		t.Errorf("expected Clone to keep the ChildArray layout")
+	}
+
+	karytree.SetLayout(bounded, karytree.SiblingList)
+	if bounded.NthChild(2).Layout() != karytree.SiblingList || !karytree.Equals(bounded, expected) {
+		t.Errorf("expected SetLayout to switch back to the SiblingList layout")
+	}
+}
+
+func TestDenseNodeUnmarshal(t *testing.T) {
+	src := karytree.NewKaryNode("a", 4)
+	b := karytree.NewKaryNode("b", 4)
+	c := karytree.NewKaryNode("c", 4)
+	src.SetNthChild(3, &b)
+	b.SetNthChild(1, &c)
+
+	gobData, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := src.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		unmarshal func(*karytree.Node[string]) error
+	}{
+		{"UnmarshalBinary", func(n *karytree.Node[string]) error { return n.UnmarshalBinary(gobData) }},
+		{"UnmarshalJSON", func(n *karytree.Node[string]) error { return n.UnmarshalJSON(jsonData) }},
+	} {
+		// the decoded tree takes the layout of the node it's decoded into
+		for _, layout := range []karytree.Layout{karytree.ChildArray, karytree.SiblingList} {
+			dst := karytree.NewKaryNode("x", 2)
+			karytree.SetLayout(&dst, layout)
+			if err := tc.unmarshal(&dst); err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if dst.NthChild(3) == nil || dst.NthChild(3).NthChild(1).Key() != "c" || !karytree.Equals(&dst, &src) {
+				t.Errorf("%s: expected %v, got %v", tc.name, &src, &dst)
+			}
+			for node := range karytree.BFSSeq(&dst) {
+				if node.Layout() != layout {
+					t.Errorf("%s: expected %v to use layout %d, got %d", tc.name, node.Key(), layout, node.Layout())
+				}
+			}
+		}
+	}
+}
+
+// denseNode creates the nodes of treeHelper with the ChildArray layout.
+func denseNode(K int) func(key int) *karytree.Node[interface{}] {
+	return func(key int) *karytree.Node[interface{}] {
+		node := karytree.NewDenseNode[interface{}](key, uint(K))
+		return &node
+	}
+}
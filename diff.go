@@ -0,0 +1,272 @@
+package karytree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrConflict is returned by Apply when an edit doesn't match the tree
+// it is applied to.
+var ErrConflict = errors.New("karytree: edit doesn't apply")
+
+// An EditOp is the kind of change made by an Edit.
+type EditOp int
+
+const (
+	// Insert adds a subtree at a free child index.
+	Insert EditOp = iota
+	// Delete removes a subtree.
+	Delete
+	// Relabel changes the key of a node, keeping its children.
+	Relabel
+	// Move changes the child index of a subtree within its parent.
+	Move
+)
+
+func (op EditOp) String() string {
+	switch op {
+	case Insert:
+		return "insert"
+	case Delete:
+		return "delete"
+	case Relabel:
+		return "relabel"
+	case Move:
+		return "move"
+	}
+	return fmt.Sprintf("EditOp(%d)", int(op))
+}
+
+// An Edit is a single change to a tree, as produced by Diff.
+type Edit[T comparable] struct {
+	Op EditOp
+	// Path holds the child indices that lead from the root to the node
+	// that is relabeled, deleted or moved, or to the position where a
+	// subtree is inserted. See Node.PathFromRoot.
+	Path []uint
+	// To is the new child index of a moved node.
+	To uint
+	// Old and New are the keys before and after a Relabel.
+	Old, New T
+	// Subtree is the inserted subtree, or the deleted one.
+	Subtree *Node[T]
+}
+
+// String formats e as a line of a diff: "+" for an insert, "-" for a
+// delete, "~" for a relabel and ">" for a move, followed by its path.
+// Subtrees are printed in the compact form of %v. An edit that Apply
+// can't make sense of, like a move of the root, is printed as its op and
+// path, e.g. "move /".
+func (e Edit[T]) String() string {
+	path := formatPath(e.Path)
+	switch e.Op {
+	case Insert:
+		return fmt.Sprintf("+ %s %v", path, e.Subtree)
+	case Delete:
+		return fmt.Sprintf("- %s %v", path, e.Subtree)
+	case Relabel:
+		return fmt.Sprintf("~ %s %v -> %v", path, e.Old, e.New)
+	case Move:
+		if len(e.Path) == 0 {
+			break // a root can't move, as Apply reports
+		}
+		to := append(append([]uint{}, e.Path[:len(e.Path)-1]...), e.To)
+		return fmt.Sprintf("> %s -> %s", path, formatPath(to))
+	}
+	return fmt.Sprintf("%v %s", e.Op, path)
+}
+
+// formatPath formats a path like a file path, e.g. /1/9 or / for a root.
+func formatPath(path []uint) string {
+	if len(path) == 0 {
+		return "/"
+	}
+	var sb strings.Builder
+	for _, n := range path {
+		fmt.Fprintf(&sb, "/%d", n)
+	}
+	return sb.String()
+}
+
+// WriteDiff writes edits to w, one per line, as formatted by Edit.String.
+func WriteDiff[T comparable](w io.Writer, edits []Edit[T]) error {
+	bw := bufio.NewWriter(w)
+	for _, e := range edits {
+		bw.WriteString(e.String())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Diff returns the edits that turn the tree rooted at a into the tree
+// rooted at b, so that Apply(a, Diff(a, b)) leaves a equal to b. Nodes
+// are matched by their child index paths: a node that only exists in a
+// is deleted, one that only exists in b is inserted, and one whose key
+// differs is relabeled. A deleted subtree that is equal to an inserted
+// sibling is moved instead. The roots of a and b must not be nil.
+//
+// The edits are in preorder, and each path is valid in the tree as left
+// by the edits before it. The inserted and deleted subtrees are copies,
+// so a and b can be modified afterwards.
+func Diff[T comparable](a, b *Node[T]) []Edit[T] {
+	edits := []Edit[T]{}
+	diff(a, b, []uint{}, &edits)
+	return edits
+}
+
+func diff[T comparable](a, b *Node[T], path []uint, edits *[]Edit[T]) {
+	if a.key != b.key {
+		*edits = append(*edits, Edit[T]{Op: Relabel, Path: path, Old: a.key, New: b.key})
+	}
+
+	var deleted, inserted []*Node[T]
+	ca, cb := a.firstChild, b.firstChild
+	for ca != nil || cb != nil {
+		switch {
+		case cb == nil || (ca != nil && ca.n < cb.n):
+			deleted = append(deleted, ca)
+			ca = ca.nextSibling
+		case ca == nil || cb.n < ca.n:
+			inserted = append(inserted, cb)
+			cb = cb.nextSibling
+		default:
+			ca, cb = ca.nextSibling, cb.nextSibling
+		}
+	}
+
+	childPath := func(n uint) []uint {
+		return append(append(make([]uint, 0, len(path)+1), path...), n)
+	}
+
+	for i, del := range deleted {
+		for j, ins := range inserted {
+			if ins != nil && subtreeEquals(del, ins) {
+				*edits = append(*edits, Edit[T]{Op: Move, Path: childPath(del.n), To: ins.n})
+				deleted[i], inserted[j] = nil, nil
+				break
+			}
+		}
+	}
+	for _, del := range deleted {
+		if del != nil {
+			*edits = append(*edits, Edit[T]{Op: Delete, Path: childPath(del.n), Subtree: Clone(del)})
+		}
+	}
+	for _, ins := range inserted {
+		if ins != nil {
+			*edits = append(*edits, Edit[T]{Op: Insert, Path: childPath(ins.n), Subtree: Clone(ins)})
+		}
+	}
+
+	ca, cb = a.firstChild, b.firstChild
+	for ca != nil && cb != nil {
+		switch {
+		case ca.n < cb.n:
+			ca = ca.nextSibling
+		case cb.n < ca.n:
+			cb = cb.nextSibling
+		default:
+			diff(ca, cb, childPath(ca.n), edits)
+			ca, cb = ca.nextSibling, cb.nextSibling
+		}
+	}
+}
+
+// subtreeEquals is like Equals, but ignores the child indices of a and
+// b themselves.
+func subtreeEquals[T comparable](a, b *Node[T]) bool {
+	if a.key != b.key {
+		return false
+	}
+	ca, cb := a.firstChild, b.firstChild
+	for ca != nil && cb != nil {
+		if !Equals(ca, cb) {
+			return false
+		}
+		ca, cb = ca.nextSibling, cb.nextSibling
+	}
+	return ca == nil && cb == nil
+}
+
+// Apply applies edits, as produced by Diff, to the tree rooted at root,
+// in order. It returns ErrConflict at the first edit that doesn't match
+// the tree: a missing node, an occupied child index, or a relabeled key
+// that isn't Old, or ErrIndexOutOfRange if an edit puts a child out of
+// range of a bounded node. The edits before it are left applied. Inserted
+// subtrees are copied, so edits can be applied more than once.
+func Apply[T comparable](root *Node[T], edits []Edit[T]) error {
+	for i, e := range edits {
+		if err := apply(root, e); err != nil {
+			return fmt.Errorf("edit %d (%v): %w", i, e, err)
+		}
+	}
+	return nil
+}
+
+func apply[T comparable](root *Node[T], e Edit[T]) error {
+	if e.Op == Relabel {
+		node := nodeAt(root, e.Path)
+		if node == nil {
+			return fmt.Errorf("%w: no node at %s", ErrConflict, formatPath(e.Path))
+		}
+		if node.key != e.Old {
+			return fmt.Errorf("%w: key at %s is %v, not %v", ErrConflict, formatPath(e.Path), node.key, e.Old)
+		}
+		node.SetKey(e.New)
+		return nil
+	}
+
+	if len(e.Path) == 0 {
+		return fmt.Errorf("%w: can't %v a root", ErrConflict, e.Op)
+	}
+	parent := nodeAt(root, e.Path[:len(e.Path)-1])
+	n := e.Path[len(e.Path)-1]
+	if parent == nil {
+		return fmt.Errorf("%w: no node at %s", ErrConflict, formatPath(e.Path[:len(e.Path)-1]))
+	}
+
+	switch e.Op {
+	case Insert:
+		if e.Subtree == nil {
+			return fmt.Errorf("%w: nothing to insert", ErrConflict)
+		}
+		if parent.NthChild(n) != nil {
+			return fmt.Errorf("%w: %s is already taken", ErrConflict, formatPath(e.Path))
+		}
+		_, err := parent.TrySetNthChild(n, Clone(e.Subtree))
+		return err
+	case Delete:
+		if parent.RemoveNthChild(n) == nil {
+			return fmt.Errorf("%w: no node at %s", ErrConflict, formatPath(e.Path))
+		}
+		return nil
+	case Move:
+		if parent.NthChild(n) == nil {
+			return fmt.Errorf("%w: no node at %s", ErrConflict, formatPath(e.Path))
+		}
+		if parent.NthChild(e.To) != nil {
+			return fmt.Errorf("%w: child %d of %s is already taken", ErrConflict, e.To, formatPath(e.Path[:len(e.Path)-1]))
+		}
+		if err := parent.checkIndex(e.To); err != nil {
+			return err
+		}
+		parent.MoveChild(n, e.To)
+		return nil
+	}
+	return fmt.Errorf("%w: unknown op %v", ErrConflict, e.Op)
+}
+
+// nodeAt follows path down from root, returning nil if it leads nowhere.
+func nodeAt[T comparable](root *Node[T], path []uint) *Node[T] {
+	node := root
+	for _, n := range path {
+		if node == nil {
+			return nil
+		}
+		node = node.NthChild(n)
+	}
+	return node
+}
@@ -0,0 +1,142 @@
+package karytree_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func mustParse(t *testing.T, s string) *karytree.Node[string] {
+	t.Helper()
+	tree, err := karytree.ParseSExpr(s, parseString)
+	if err != nil {
+		t.Fatalf("parse %q failed: %v", s, err)
+	}
+	return tree
+}
+
+func TestDiff(t *testing.T) {
+	a := mustParse(t, "(a (b e f) c [5](d g))")
+	b := mustParse(t, "(x (b e [3]h) [4](d g) [7]i)")
+
+	edits := karytree.Diff(a, b)
+
+	var sb strings.Builder
+	if err := karytree.WriteDiff(&sb, edits); err != nil {
+		t.Fatalf("WriteDiff failed: %v", err)
+	}
+	expected := `~ / a -> x
+> /5 -> /4
+- /1 c
++ /7 i
+- /0/1 f
++ /0/3 h
+`
+	if sb.String() != expected {
+		t.Errorf("unexpected diff:\n%s\nexpected:\n%s", sb.String(), expected)
+	}
+
+	if err := karytree.Apply(a, edits); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+	if !karytree.Equals(a, b) {
+		t.Errorf("expected %v after patching, got %v", b, a)
+	}
+}
+
+func TestDiffEqualTrees(t *testing.T) {
+	a := mustParse(t, "(a (b e f) c (d g))")
+	b := mustParse(t, "(a (b e f) c (d g))")
+
+	if edits := karytree.Diff(a, b); len(edits) != 0 {
+		t.Errorf("expected no edits between equal trees, got %v", edits)
+	}
+}
+
+func TestDiffEditsAreCopies(t *testing.T) {
+	a := mustParse(t, "(a b)")
+	b := mustParse(t, "(a b (c d))")
+
+	edits := karytree.Diff(a, b)
+	if len(edits) != 1 || edits[0].Op != karytree.Insert || !reflect.DeepEqual(edits[0].Path, []uint{1}) {
+		t.Fatalf("expected a single insert at /1, got %v", edits)
+	}
+
+	b.NthChild(1).SetKey("changed")
+	other := mustParse(t, "(a b)")
+	for _, tree := range []*karytree.Node[string]{a, other} {
+		if err := karytree.Apply(tree, edits); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+		if tree.String() != "(a b (c d))" {
+			t.Errorf("expected (a b (c d)), got %v", tree)
+		}
+	}
+	if a.NthChild(1) == other.NthChild(1) {
+		t.Errorf("expected each Apply to insert its own copy")
+	}
+}
+
+func TestApplyConflicts(t *testing.T) {
+	for _, e := range []karytree.Edit[string]{
+		{Op: karytree.Relabel, Path: []uint{0}, Old: "x", New: "y"},
+		{Op: karytree.Relabel, Path: []uint{9}, Old: "b", New: "y"},
+		{Op: karytree.Delete, Path: []uint{3}},
+		{Op: karytree.Delete, Path: []uint{}},
+		{Op: karytree.Insert, Path: []uint{1}, Subtree: mustParse(t, "z")},
+		{Op: karytree.Insert, Path: []uint{5, 0}, Subtree: mustParse(t, "z")},
+		{Op: karytree.Move, Path: []uint{0}, To: 1},
+		{Op: karytree.Move, Path: []uint{2}, To: 3},
+		{Op: karytree.Move, Path: []uint{}, To: 1},
+	} {
+		tree := mustParse(t, "(a b c)")
+		if err := karytree.Apply(tree, []karytree.Edit[string]{e}); !errors.Is(err, karytree.ErrConflict) {
+			t.Errorf("%v: expected ErrConflict, got %v", e, err)
+		}
+		if tree.String() != "(a b c)" {
+			t.Errorf("%v: expected the tree to be unchanged, got %v", e, tree)
+		}
+	}
+
+	if s := (karytree.Edit[string]{Op: karytree.Move, To: 1}).String(); s != "move /" {
+		t.Errorf("expected a root move to print as move /, got %s", s)
+	}
+
+	bounded := karytree.Binary("a")
+	err := karytree.Apply(&bounded, []karytree.Edit[string]{{Op: karytree.Insert, Path: []uint{2}, Subtree: mustParse(t, "z")}})
+	if !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange, got %v", err)
+	}
+}
+
+func randomTree(r *rand.Rand, depth int) *karytree.Node[int] {
+	node := karytree.NewNode(r.Intn(3))
+	if depth == 0 {
+		return &node
+	}
+	for i := uint(0); i < 6; i++ {
+		if r.Intn(2) == 0 {
+			node.SetNthChild(i, randomTree(r, depth-1))
+		}
+	}
+	return &node
+}
+
+func TestDiffApplyRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		a := randomTree(r, 3)
+		b := randomTree(r, 3)
+
+		if err := karytree.Apply(a, karytree.Diff(a, b)); err != nil {
+			t.Fatalf("apply failed: %v", err)
+		}
+		if !karytree.Equals(a, b) {
+			t.Fatalf("expected %v after patching, got %v", b, a)
+		}
+	}
+}
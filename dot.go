@@ -0,0 +1,133 @@
+package karytree
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DOTOptions configures WriteDOT. The zero value draws the logical
+// parent/child edges of a tree, with nodes labeled by their keys.
+type DOTOptions[T comparable] struct {
+	// Name is the name of the digraph. It defaults to "tree".
+	Name string
+
+	// SiblingLinks draws the raw firstChild/nextSibling links of the
+	// child-sibling layout instead of the parent/child edges, with the
+	// siblings of each node ranked on the same line.
+	SiblingLinks bool
+
+	// Label returns the label of a node. It defaults to printing the key
+	// with fmt.Sprint.
+	Label func(node *Node[T]) string
+
+	// Attrs returns extra DOT attributes for a node, e.g. "shape" or
+	// "color". Attributes are written in sorted order, and an Attrs
+	// "label" overrides Label.
+	Attrs func(node *Node[T]) map[string]string
+}
+
+// WriteDOT writes the tree rooted at root to w in the Graphviz DOT
+// language. Each edge is labeled with the child index n of the node it
+// points to. opts may be nil for the defaults.
+func WriteDOT[T comparable](w io.Writer, root *Node[T], opts *DOTOptions[T]) error {
+	if opts == nil {
+		opts = &DOTOptions[T]{}
+	}
+	name := opts.Name
+	if name == "" {
+		name = "tree"
+	}
+
+	bw := bufio.NewWriter(w)
+	ids := map[*Node[T]]int{}
+
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(name))
+	for node := range BFSSeq(root) {
+		ids[node] = len(ids)
+
+		attrs := map[string]string{}
+		if opts.Label != nil {
+			attrs["label"] = opts.Label(node)
+		} else {
+			attrs["label"] = fmt.Sprint(node.key)
+		}
+		if opts.Attrs != nil {
+			for k, v := range opts.Attrs(node) {
+				attrs[k] = v
+			}
+		}
+		fmt.Fprintf(bw, "\tn%d%s;\n", ids[node], dotAttrs(attrs))
+	}
+
+	for node := range BFSSeq(root) {
+		if node.firstChild == nil {
+			continue
+		}
+
+		if !opts.SiblingLinks {
+			for child := node.firstChild; child != nil; child = child.nextSibling {
+				fmt.Fprintf(bw, "\tn%d -> n%d [label=\"%d\"];\n", ids[node], ids[child], child.n)
+			}
+			continue
+		}
+
+		fmt.Fprintf(bw, "\tn%d -> n%d [label=\"firstChild %d\"];\n", ids[node], ids[node.firstChild], node.firstChild.n)
+		rank := []string{}
+		for child := node.firstChild; child != nil; child = child.nextSibling {
+			rank = append(rank, fmt.Sprintf("n%d", ids[child]))
+			if next := child.nextSibling; next != nil {
+				fmt.Fprintf(bw, "\tn%d -> n%d [label=\"nextSibling %d\"];\n", ids[child], ids[next], next.n)
+			}
+		}
+		if len(rank) > 1 {
+			fmt.Fprintf(bw, "\t{ rank=same; %s; }\n", strings.Join(rank, "; "))
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+
+	return bw.Flush()
+}
+
+// dotAttrs formats attrs as a DOT attribute list, in sorted order.
+func dotAttrs(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	sb.WriteString(" [")
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(dotQuote(attrs[k]))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,101 @@
+package karytree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func constructDOTTree() *karytree.Node[string] {
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	a.SetNthChild(4, &c)
+	a.SetNthChild(1, &b)
+	b.SetNthChild(9, &d)
+	return &a
+}
+
+func TestWriteDOT(t *testing.T) {
+	a := constructDOTTree()
+
+	var sb strings.Builder
+	if err := karytree.WriteDOT(&sb, a, nil); err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+
+	expected := `digraph "tree" {
+	n0 [label="a"];
+	n1 [label="b"];
+	n2 [label="c"];
+	n3 [label="d"];
+	n0 -> n1 [label="1"];
+	n0 -> n2 [label="4"];
+	n1 -> n3 [label="9"];
+}
+`
+	if sb.String() != expected {
+		t.Errorf("unexpected DOT:\n%s\nexpected:\n%s", sb.String(), expected)
+	}
+}
+
+func TestWriteDOTSiblingLinks(t *testing.T) {
+	a := constructDOTTree()
+
+	var sb strings.Builder
+	err := karytree.WriteDOT(&sb, a, &karytree.DOTOptions[string]{
+		Name:         "siblings",
+		SiblingLinks: true,
+	})
+	if err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+
+	expected := `digraph "siblings" {
+	n0 [label="a"];
+	n1 [label="b"];
+	n2 [label="c"];
+	n3 [label="d"];
+	n0 -> n1 [label="firstChild 1"];
+	n1 -> n2 [label="nextSibling 4"];
+	{ rank=same; n1; n2; }
+	n1 -> n3 [label="firstChild 9"];
+}
+`
+	if sb.String() != expected {
+		t.Errorf("unexpected DOT:\n%s\nexpected:\n%s", sb.String(), expected)
+	}
+}
+
+func TestWriteDOTCallbacks(t *testing.T) {
+	a := karytree.NewNode(`say "hi"`)
+	b := karytree.NewNode("leaf")
+	a.SetNthChild(0, &b)
+
+	var sb strings.Builder
+	err := karytree.WriteDOT(&sb, &a, &karytree.DOTOptions[string]{
+		Label: func(node *karytree.Node[string]) string {
+			return node.Key() + "\n" + strings.Repeat("*", node.Depth())
+		},
+		Attrs: func(node *karytree.Node[string]) map[string]string {
+			if node.IsLeaf() {
+				return map[string]string{"shape": "box", "color": "red"}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("WriteDOT failed: %v", err)
+	}
+
+	for _, line := range []string{
+		`n0 [label="say \"hi\"\n"];`,
+		`n1 [color="red", label="leaf\n*", shape="box"];`,
+	} {
+		if !strings.Contains(sb.String(), line) {
+			t.Errorf("expected DOT to contain %s, got:\n%s", line, sb.String())
+		}
+	}
+}
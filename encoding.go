@@ -0,0 +1,434 @@
+package karytree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"slices"
+)
+
+/*
+The binary wire format is a sequence of frames, each holding one tree:
+
+	magic "KARY" | version | node ... | crc32
+
+Nodes are written in preorder, each as
+
+	uvarint n | uvarint k | uvarint len(key) | key | uvarint numChildren
+
+where the key bytes come from a KeyCodec. A node's children follow it in
+ascending order of n, so the tree can be rebuilt, or consumed one node at
+a time, without knowing its size up front. The trailing crc32 (IEEE,
+big-endian) covers every byte of the frame before it.
+*/
+
+const (
+	wireMagic   = "KARY"
+	wireVersion = 1
+
+	// keyChunk bounds how much of a key a Decoder reads at a time.
+	keyChunk = 4096
+)
+
+var (
+	// ErrChecksum is returned by a Decoder when a frame's checksum
+	// doesn't match its contents.
+	ErrChecksum = errors.New("karytree: checksum mismatch")
+
+	// ErrMalformed is returned by an Encoder or Decoder when a stream
+	// doesn't describe a valid tree.
+	ErrMalformed = errors.New("karytree: malformed tree stream")
+)
+
+// A KeyCodec converts keys of type T to and from bytes for the binary
+// wire format.
+type KeyCodec[T comparable] interface {
+	// AppendKey appends the encoding of key to dst.
+	AppendKey(dst []byte, key T) ([]byte, error)
+	// DecodeKey decodes a key from exactly the bytes written by AppendKey.
+	// src may be reused once DecodeKey returns, so it must not be kept.
+	DecodeKey(src []byte) (T, error)
+}
+
+// StringCodec is a KeyCodec for string keys.
+type StringCodec struct{}
+
+// AppendKey appends the bytes of key.
+func (StringCodec) AppendKey(dst []byte, key string) ([]byte, error) {
+	return append(dst, key...), nil
+}
+
+// DecodeKey converts src to a string.
+func (StringCodec) DecodeKey(src []byte) (string, error) {
+	return string(src), nil
+}
+
+// Signed is the set of signed integer types.
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Unsigned is the set of unsigned integer types.
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntCodec is a KeyCodec for signed integer keys, encoded as varints.
+type IntCodec[T Signed] struct{}
+
+// AppendKey appends key as a varint.
+func (IntCodec[T]) AppendKey(dst []byte, key T) ([]byte, error) {
+	return binary.AppendVarint(dst, int64(key)), nil
+}
+
+// DecodeKey decodes a varint key.
+func (IntCodec[T]) DecodeKey(src []byte) (T, error) {
+	v, l := binary.Varint(src)
+	if l <= 0 || l != len(src) || int64(T(v)) != v {
+		return 0, fmt.Errorf("%w: bad integer key", ErrMalformed)
+	}
+	return T(v), nil
+}
+
+// UintCodec is a KeyCodec for unsigned integer keys, encoded as uvarints.
+type UintCodec[T Unsigned] struct{}
+
+// AppendKey appends key as a uvarint.
+func (UintCodec[T]) AppendKey(dst []byte, key T) ([]byte, error) {
+	return binary.AppendUvarint(dst, uint64(key)), nil
+}
+
+// DecodeKey decodes a uvarint key.
+func (UintCodec[T]) DecodeKey(src []byte) (T, error) {
+	v, l := binary.Uvarint(src)
+	if l <= 0 || l != len(src) || uint64(T(v)) != v {
+		return 0, fmt.Errorf("%w: bad unsigned integer key", ErrMalformed)
+	}
+	return T(v), nil
+}
+
+// A Record is a single node in the binary wire format, without links to
+// other nodes. Records are written and read in preorder.
+type Record[T comparable] struct {
+	Key         T
+	N           uint
+	K           uint
+	NumChildren int
+	// Depth is set by Decoder.Next and ignored by Encoder.WriteRecord.
+	Depth int
+}
+
+// frame tracks the nodes of a tree that still have children to come.
+type frame struct {
+	remaining int
+	lastN     uint
+	k         uint
+	seen      bool
+}
+
+// pushChild validates the index of the next child of the top of stack,
+// and consumes it.
+func pushChild(stack []frame, n uint) error {
+	top := &stack[len(stack)-1]
+	if top.k != 0 && n >= top.k {
+		return fmt.Errorf("%w: %d >= k=%d", ErrIndexOutOfRange, n, top.k)
+	}
+	if top.seen && n <= top.lastN {
+		return fmt.Errorf("%w: child index %d after %d", ErrMalformed, n, top.lastN)
+	}
+	top.remaining--
+	top.lastN = n
+	top.seen = true
+	return nil
+}
+
+// popDone drops the nodes whose children have all been seen.
+func popDone(stack []frame) []frame {
+	for len(stack) > 0 && stack[len(stack)-1].remaining == 0 {
+		stack = stack[:len(stack)-1]
+	}
+	return stack
+}
+
+// An Encoder writes trees to an io.Writer in the binary wire format.
+type Encoder[T comparable] struct {
+	w     io.Writer
+	codec KeyCodec[T]
+	crc   hash.Hash32
+	buf   []byte
+	key   []byte
+	stack []frame
+	open  bool
+	err   error
+}
+
+// NewEncoder returns an Encoder that writes to w, using codec for keys.
+// Wrap w in a bufio.Writer if it is expensive to write to. Once a write
+// to w fails, the Encoder keeps returning that error.
+func NewEncoder[T comparable](w io.Writer, codec KeyCodec[T]) *Encoder[T] {
+	return &Encoder[T]{w: w, codec: codec, crc: crc32.NewIEEE()}
+}
+
+// Encode writes the tree rooted at root as one frame.
+func (e *Encoder[T]) Encode(root *Node[T]) error {
+	if root == nil {
+		return fmt.Errorf("%w: can't encode a nil tree", ErrMalformed)
+	}
+	if e.open {
+		return fmt.Errorf("%w: a tree is already being written", ErrMalformed)
+	}
+	return e.encode(root)
+}
+
+func (e *Encoder[T]) encode(node *Node[T]) error {
+	err := e.WriteRecord(Record[T]{
+		Key:         node.key,
+		N:           node.n,
+		K:           node.k,
+		NumChildren: node.NumChildren(),
+	})
+	if err != nil {
+		return err
+	}
+	for child := node.firstChild; child != nil; child = child.nextSibling {
+		if err := e.encode(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteRecord writes a single node, so that trees can be streamed
+// without holding them in memory. Records must be written in preorder:
+// the root first, then each of its NumChildren children (and their
+// subtrees) in ascending order of N. The frame is finished, and its
+// checksum written, as soon as the last node of the tree is written.
+func (e *Encoder[T]) WriteRecord(r Record[T]) error {
+	if e.err != nil {
+		return e.err
+	}
+	if r.NumChildren < 0 {
+		return fmt.Errorf("%w: negative number of children", ErrMalformed)
+	}
+
+	var err error
+	e.key, err = e.codec.AppendKey(e.key[:0], r.Key)
+	if err != nil {
+		return err
+	}
+
+	e.buf = e.buf[:0]
+	if !e.open {
+		e.crc.Reset()
+		e.buf = append(e.buf, wireMagic...)
+		e.buf = append(e.buf, wireVersion)
+	} else if err := pushChild(e.stack, r.N); err != nil {
+		return err
+	}
+
+	e.buf = binary.AppendUvarint(e.buf, uint64(r.N))
+	e.buf = binary.AppendUvarint(e.buf, uint64(r.K))
+	e.buf = binary.AppendUvarint(e.buf, uint64(len(e.key)))
+	e.buf = append(e.buf, e.key...)
+	e.buf = binary.AppendUvarint(e.buf, uint64(r.NumChildren))
+
+	e.open = true
+	e.stack = popDone(append(e.stack, frame{remaining: r.NumChildren, k: r.K}))
+
+	e.crc.Write(e.buf)
+	if len(e.stack) == 0 {
+		e.open = false
+		e.buf = binary.BigEndian.AppendUint32(e.buf, e.crc.Sum32())
+	}
+
+	if _, err := e.w.Write(e.buf); err != nil {
+		e.err = err
+	}
+	return e.err
+}
+
+// crcReader is a bufio.Reader that hashes everything read through it.
+type crcReader struct {
+	r   *bufio.Reader
+	sum uint32
+}
+
+func (c *crcReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.sum = crc32.Update(c.sum, crc32.IEEETable, p[:n])
+	return n, err
+}
+
+func (c *crcReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.sum = crc32.Update(c.sum, crc32.IEEETable, []byte{b})
+	}
+	return b, err
+}
+
+// A Decoder reads trees written by an Encoder from an io.Reader.
+type Decoder[T comparable] struct {
+	r     *crcReader
+	codec KeyCodec[T]
+	key   []byte
+	stack []frame
+	open  bool
+	err   error
+}
+
+// NewDecoder returns a Decoder that reads from r, using codec for keys.
+// The Decoder buffers its reads, so it may read past the end of the
+// last frame. Once Next or Decode returns an error other than io.EOF,
+// the Decoder keeps returning that error.
+func NewDecoder[T comparable](r io.Reader, codec KeyCodec[T]) *Decoder[T] {
+	return &Decoder[T]{
+		r:     &crcReader{r: bufio.NewReader(r)},
+		codec: codec,
+	}
+}
+
+// Decode reads the next frame and rebuilds its tree. It returns io.EOF
+// if there are no more frames.
+func (d *Decoder[T]) Decode() (*Node[T], error) {
+	if d.open {
+		return nil, fmt.Errorf("%w: a tree is already being read", ErrMalformed)
+	}
+
+	var root *Node[T]
+	parents := []*Node[T]{}
+
+	for {
+		r, err := d.Next()
+		if err != nil {
+			return nil, err
+		}
+
+		node := NewKaryNode(r.Key, r.K)
+		node.n = r.N
+		if r.Depth == 0 {
+			root = &node
+		} else {
+			parents = parents[:r.Depth]
+			parents[r.Depth-1].setNthChild(r.N, &node)
+		}
+		parents = append(parents, &node)
+
+		if !d.open {
+			return root, nil
+		}
+	}
+}
+
+// Next reads a single node, so that trees can be consumed without
+// holding them in memory. Nodes are returned in preorder, with their
+// Depth set. When the last node of a frame is read, the checksum is
+// verified before it is returned. Next returns io.EOF if there are no
+// more frames.
+func (d *Decoder[T]) Next() (Record[T], error) {
+	if d.err != nil {
+		return Record[T]{}, d.err
+	}
+	r, err := d.next()
+	if err != nil && err != io.EOF {
+		d.err = err
+	}
+	return r, err
+}
+
+func (d *Decoder[T]) next() (Record[T], error) {
+	var r Record[T]
+
+	if !d.open {
+		d.r.sum = 0
+		var header [len(wireMagic) + 1]byte
+		if _, err := io.ReadFull(d.r, header[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				err = fmt.Errorf("%w: truncated header", ErrMalformed)
+			}
+			return r, err
+		}
+		if string(header[:len(wireMagic)]) != wireMagic {
+			return r, fmt.Errorf("%w: bad magic %q", ErrMalformed, header[:len(wireMagic)])
+		}
+		if header[len(wireMagic)] != wireVersion {
+			return r, fmt.Errorf("%w: unsupported version %d", ErrMalformed, header[len(wireMagic)])
+		}
+	}
+
+	n, err := d.readUvarint()
+	if err != nil {
+		return r, err
+	}
+	k, err := d.readUvarint()
+	if err != nil {
+		return r, err
+	}
+	keyLen, err := d.readUvarint()
+	if err != nil {
+		return r, err
+	}
+	// grow the key chunk by chunk rather than allocating keyLen up
+	// front, in case it's corrupt
+	d.key = d.key[:0]
+	for keyLen > 0 {
+		chunk := min(keyLen, keyChunk)
+		start := len(d.key)
+		d.key = slices.Grow(d.key, int(chunk))[:start+int(chunk)]
+		if _, err := io.ReadFull(d.r, d.key[start:]); err != nil {
+			return r, fmt.Errorf("%w: truncated key", ErrMalformed)
+		}
+		keyLen -= chunk
+	}
+	numChildren, err := d.readUvarint()
+	if err != nil {
+		return r, err
+	}
+	if numChildren > uint64(int(^uint(0)>>1)) {
+		return r, fmt.Errorf("%w: bad number of children", ErrMalformed)
+	}
+
+	r.N = uint(n)
+	r.K = uint(k)
+	r.NumChildren = int(numChildren)
+	r.Depth = len(d.stack)
+
+	if d.open {
+		if err := pushChild(d.stack, r.N); err != nil {
+			return r, err
+		}
+	}
+	r.Key, err = d.codec.DecodeKey(d.key)
+	if err != nil {
+		return r, err
+	}
+
+	d.open = true
+	d.stack = popDone(append(d.stack, frame{remaining: r.NumChildren, k: r.K}))
+
+	if len(d.stack) == 0 {
+		d.open = false
+		sum := d.r.sum
+		var trailer [4]byte
+		if _, err := io.ReadFull(d.r.r, trailer[:]); err != nil {
+			return r, fmt.Errorf("%w: truncated checksum", ErrMalformed)
+		}
+		if binary.BigEndian.Uint32(trailer[:]) != sum {
+			return r, ErrChecksum
+		}
+	}
+
+	return r, nil
+}
+
+func (d *Decoder[T]) readUvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,186 @@
+package karytree_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func constructSparseUintTree() *karytree.Node[uint] {
+	a := karytree.NewKaryNode[uint](0, 32)
+	b := karytree.NewKaryNode[uint](1, 32)
+	c := karytree.NewKaryNode[uint](1<<40, 32)
+	d := karytree.NewKaryNode[uint](3, 32)
+	e := karytree.NewNode[uint](4)
+
+	a.SetNthChild(17, &b)
+	a.SetNthChild(3, &c)
+	c.SetNthChild(31, &d)
+	b.SetNthChild(0, &e)
+
+	return &a
+}
+
+func TestEncoderRoundTrip(t *testing.T) {
+	a := constructSparseUintTree()
+
+	var buf bytes.Buffer
+	if err := karytree.NewEncoder[uint](&buf, karytree.UintCodec[uint]{}).Encode(a); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+
+	decoded, err := karytree.NewDecoder[uint](&buf, karytree.UintCodec[uint]{}).Decode()
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+
+	if !karytree.Equals(a, decoded) {
+		t.Errorf("decoded tree doesn't equal the original")
+	}
+	if decoded.K() != 32 || decoded.NthChild(17).NthChild(0).K() != 0 {
+		t.Errorf("arity wasn't preserved")
+	}
+}
+
+func TestEncoderMultipleFrames(t *testing.T) {
+	trees := []karytree.Node[int]{
+		karytree.NewNode(-1),
+		karytree.NewNode(2),
+	}
+	child := karytree.NewNode(-300)
+	trees[1].SetNthChild(1000, &child)
+
+	var buf bytes.Buffer
+	enc := karytree.NewEncoder[int](&buf, karytree.IntCodec[int]{})
+	for i := range trees {
+		if err := enc.Encode(&trees[i]); err != nil {
+			t.Fatalf("encode failed: %v", err)
+		}
+	}
+
+	dec := karytree.NewDecoder[int](&buf, karytree.IntCodec[int]{})
+	for i := range trees {
+		decoded, err := dec.Decode()
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if !karytree.Equals(&trees[i], decoded) {
+			t.Errorf("decoded tree %d doesn't equal the original", i)
+		}
+	}
+
+	if _, err := dec.Decode(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last frame, got %v", err)
+	}
+}
+
+func TestEncoderStreaming(t *testing.T) {
+	records := []karytree.Record[string]{
+		{Key: "a", NumChildren: 2},
+		{Key: "b", N: 1, NumChildren: 1},
+		{Key: "d", N: 9},
+		{Key: "c", N: 4},
+	}
+
+	var buf bytes.Buffer
+	enc := karytree.NewEncoder[string](&buf, karytree.StringCodec{})
+	for _, r := range records {
+		if err := enc.WriteRecord(r); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	dec := karytree.NewDecoder[string](bytes.NewReader(buf.Bytes()), karytree.StringCodec{})
+	depths := []int{0, 1, 2, 1}
+	for i, expected := range records {
+		r, err := dec.Next()
+		if err != nil {
+			t.Fatalf("next failed: %v", err)
+		}
+		expected.Depth = depths[i]
+		if r != expected {
+			t.Errorf("expected record %+v, got %+v", expected, r)
+		}
+	}
+	if _, err := dec.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	a.SetNthChild(4, &c)
+	a.SetNthChild(1, &b)
+	b.SetNthChild(9, &d)
+
+	decoded, err := karytree.NewDecoder[string](&buf, karytree.StringCodec{}).Decode()
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if !karytree.Equals(&a, decoded) {
+		t.Errorf("streamed tree doesn't decode to the expected tree")
+	}
+}
+
+func TestEncoderRejectsInvalidStreams(t *testing.T) {
+	enc := karytree.NewEncoder[string](io.Discard, karytree.StringCodec{})
+	enc.WriteRecord(karytree.Record[string]{Key: "a", NumChildren: 2})
+	enc.WriteRecord(karytree.Record[string]{Key: "b", N: 5})
+	if err := enc.WriteRecord(karytree.Record[string]{Key: "c", N: 5}); !errors.Is(err, karytree.ErrMalformed) {
+		t.Errorf("expected ErrMalformed for out of order children, got %v", err)
+	}
+
+	enc = karytree.NewEncoder[string](io.Discard, karytree.StringCodec{})
+	enc.WriteRecord(karytree.Record[string]{Key: "a", K: 2, NumChildren: 1})
+	if err := enc.WriteRecord(karytree.Record[string]{Key: "b", N: 2}); !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	if err := enc.Encode(nil); err == nil {
+		t.Errorf("expected an error when encoding a nil tree")
+	}
+}
+
+func TestDecoderDetectsCorruption(t *testing.T) {
+	a := constructSparseUintTree()
+
+	var buf bytes.Buffer
+	if err := karytree.NewEncoder[uint](&buf, karytree.UintCodec[uint]{}).Encode(a); err != nil {
+		t.Fatalf("encode failed: %v", err)
+	}
+	data := buf.Bytes()
+
+	decode := func(data []byte) error {
+		_, err := karytree.NewDecoder[uint](bytes.NewReader(data), karytree.UintCodec[uint]{}).Decode()
+		return err
+	}
+
+	// flip a bit in the last key, which keeps the structure valid
+	corrupt := append([]byte{}, data...)
+	corrupt[len(corrupt)-6] ^= 1
+	if err := decode(corrupt); !errors.Is(err, karytree.ErrChecksum) {
+		t.Errorf("expected ErrChecksum, got %v", err)
+	}
+
+	for i := 1; i < len(data); i++ {
+		if err := decode(data[:i]); !errors.Is(err, karytree.ErrMalformed) {
+			t.Errorf("expected ErrMalformed for %d truncated bytes, got %v", len(data)-i, err)
+		}
+	}
+
+	corrupt = append([]byte{}, data...)
+	corrupt[0] = 'X'
+	if err := decode(corrupt); !errors.Is(err, karytree.ErrMalformed) {
+		t.Errorf("expected ErrMalformed for bad magic, got %v", err)
+	}
+
+	corrupt = append([]byte{}, data...)
+	corrupt[4] = 99
+	if err := decode(corrupt); !errors.Is(err, karytree.ErrMalformed) {
+		t.Errorf("expected ErrMalformed for bad version, got %v", err)
+	}
+}
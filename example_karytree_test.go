@@ -48,3 +48,24 @@ func ExampleEquals() {
 	fmt.Println(karytree.Equals(&a, &a_))
 	// Output: true
 }
+
+func ExampleBFSSeq() {
+	key := uint(0)
+	tree := karytree.NewNode[uint](key)
+	key++
+
+	for i := uint(0); i < uint(4); i++ {
+		newNode := karytree.NewNode(key)
+		key++
+		tree.SetNthChild(i, &newNode)
+	}
+
+	for node := range karytree.BFSSeq(&tree) {
+		if node.Key() == 3 {
+			break
+		}
+		fmt.Printf("%d ", node.Key())
+	}
+
+	// Output: 0 1 2
+}
@@ -0,0 +1,85 @@
+package karytree
+
+// defaultSlabSize is the number of nodes in a slab of a Forest, if
+// NewForest isn't given one.
+const defaultSlabSize = 1024
+
+// A Forest allocates nodes from contiguous slabs instead of one by one,
+// which cuts the number of allocations, and the work of the garbage
+// collector, for trees with many nodes. Its nodes are ordinary nodes:
+// SetNthChild, NthChild, BFS and the rest of the API work on them as
+// usual, and they can be linked with nodes from elsewhere.
+//
+// All the nodes of a Forest are freed together by Reset, which recycles
+// the slabs for the next trees. A Forest is not safe for concurrent use.
+type Forest[T comparable] struct {
+	slabs    [][]Node[T]
+	slab     int
+	used     int
+	slabSize int
+}
+
+// NewForest creates a Forest that allocates slabs of slabSize nodes. A
+// slabSize <= 0 picks a default.
+func NewForest[T comparable](slabSize int) *Forest[T] {
+	if slabSize <= 0 {
+		slabSize = defaultSlabSize
+	}
+	return &Forest[T]{slabSize: slabSize}
+}
+
+// alloc returns a zeroed node from the current slab, moving on to the
+// next slab, or allocating one, when it is full. Slabs are never grown
+// in place, so the nodes never move.
+func (f *Forest[T]) alloc() *Node[T] {
+	if f.slab < len(f.slabs) && f.used == len(f.slabs[f.slab]) {
+		f.slab++
+		f.used = 0
+	}
+	if f.slab == len(f.slabs) {
+		f.slabs = append(f.slabs, make([]Node[T], f.slabSize))
+	}
+	node := &f.slabs[f.slab][f.used]
+	f.used++
+	return node
+}
+
+// NewNode creates a new node with data key in f, like NewNode. Its
+// arity is unbounded.
+func (f *Forest[T]) NewNode(key T) *Node[T] {
+	node := f.alloc()
+	node.key = key
+	return node
+}
+
+// NewKaryNode creates a new node with data key in f which accepts at
+// most k children, like NewKaryNode.
+func (f *Forest[T]) NewKaryNode(key T, k uint) *Node[T] {
+	node := f.NewNode(key)
+	node.k = k
+	return node
+}
+
+// Len counts the nodes allocated in f since it was created or Reset.
+func (f *Forest[T]) Len() int {
+	if len(f.slabs) == 0 {
+		return 0
+	}
+	return f.slab*f.slabSize + f.used
+}
+
+// Reset frees all the nodes of f at once, keeping its slabs to allocate
+// new nodes from. The nodes are zeroed so that they don't keep their
+// keys alive, and must not be used anymore; nodes from elsewhere that
+// are linked to them should be detached first.
+func (f *Forest[T]) Reset() {
+	for i := 0; i < len(f.slabs) && i <= f.slab; i++ {
+		used := len(f.slabs[i])
+		if i == f.slab {
+			used = f.used
+		}
+		clear(f.slabs[i][:used])
+	}
+	f.slab = 0
+	f.used = 0
+}
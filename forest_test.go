@@ -0,0 +1,74 @@
+package karytree_test
+
+import (
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+// forestNode allocates the nodes of treeHelper in f.
+func forestNode(f *karytree.Forest[interface{}]) func(key int) *karytree.Node[interface{}] {
+	return func(key int) *karytree.Node[interface{}] {
+		return f.NewNode(key)
+	}
+}
+
+func TestForestMatchesHeapTrees(t *testing.T) {
+	f := karytree.NewForest[interface{}](100)
+
+	for _, K := range []int{2, 8} {
+		complete := karyTreeKCompleteHelper(K)
+		sparse := karyTreeKSparseHelper(K)
+		verySparse := karyTreeKVerySparseHelper(K)
+
+		if !karytree.Equals(complete, treeHelper(K, completeK, forestNode(f))) {
+			t.Errorf("K=%d: expected the complete forest tree to equal the heap tree", K)
+		}
+		if !karytree.Equals(sparse, treeHelper(K, sparseK, forestNode(f))) {
+			t.Errorf("K=%d: expected the sparse forest tree to equal the heap tree", K)
+		}
+		if !karytree.Equals(verySparse, treeHelper(K, verySparseK, forestNode(f))) {
+			t.Errorf("K=%d: expected the very sparse forest tree to equal the heap tree", K)
+		}
+	}
+
+	// K=2: 15 + 4 + 4, K=8: 585 + 85 + 4
+	if f.Len() != 697 {
+		t.Errorf("expected 697 nodes, got %d", f.Len())
+	}
+}
+
+func TestForestReset(t *testing.T) {
+	f := karytree.NewForest[interface{}](16)
+	tree := treeHelper(8, completeK, forestNode(f))
+
+	count := 0
+	for range karytree.BFSSeq(tree) {
+		count++
+	}
+	if count != f.Len() {
+		t.Errorf("expected BFS to visit the %d nodes of the forest, got %d", f.Len(), count)
+	}
+
+	f.Reset()
+	if f.Len() != 0 {
+		t.Errorf("expected an empty forest after Reset, got %d nodes", f.Len())
+	}
+	if tree.Key() != nil || tree.FirstChild() != nil {
+		t.Errorf("expected Reset to zero the nodes")
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		f.Reset()
+		treeHelper(8, completeK, forestNode(f))
+	})
+	// only the interface{} keys above 255 and the closures are allocated
+	if allocs > 600 {
+		t.Errorf("expected the slabs to be reused after Reset, got %v allocs", allocs)
+	}
+
+	bounded := f.NewKaryNode("bounded", 2)
+	if _, err := bounded.TrySetNthChild(2, f.NewNode("child")); err == nil {
+		t.Errorf("expected a bounded forest node to reject index 2")
+	}
+}
@@ -0,0 +1,143 @@
+package karytree
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// String returns the compact one-line form of the tree rooted at k, as
+// printed by %v.
+func (k *Node[T]) String() string {
+	if k == nil {
+		return "<nil>"
+	}
+	var sb strings.Builder
+	k.writeCompact(&sb, -1)
+	return sb.String()
+}
+
+// Format implements fmt.Formatter. The verbs are:
+//
+//	%v, %s  the compact one-line form, e.g. (a [1](b [9]d) [4]c)
+//	%#v     an indented tree, like the output of the tree command
+//	%+v     an indented tree that also shows every child index and arity
+//
+// In the compact form, a node with children is written as a
+// parenthesized list of its key followed by its children, and a leaf as
+// its key. Keys that contain spaces, parentheses, brackets or quotes are
+// quoted. Like the indented tree, a child is prefixed by its index [n]
+// only if its index isn't one more than its previous sibling's (or 0 for
+// the first child), so dense trees print without any indices.
+//
+// A precision limits the depth that is printed, e.g. %.2v; deeper
+// children are elided as "...".
+func (k *Node[T]) Format(f fmt.State, verb rune) {
+	if k == nil {
+		io.WriteString(f, "<nil>")
+		return
+	}
+
+	maxDepth, ok := f.Precision()
+	if !ok {
+		maxDepth = -1
+	}
+
+	switch {
+	case verb == 'v' && f.Flag('+'):
+		k.writeTree(f, maxDepth, true)
+	case verb == 'v' && f.Flag('#'):
+		k.writeTree(f, maxDepth, false)
+	case verb == 'v' || verb == 's':
+		k.writeCompact(f, maxDepth)
+	default:
+		fmt.Fprintf(f, "%%!%c(*karytree.Node=%s)", verb, k.String())
+	}
+}
+
+// sparse reports whether child needs an explicit index, given the
+// sibling that precedes it.
+func sparse[T comparable](prev, child *Node[T]) bool {
+	if prev == nil {
+		return child.n != 0
+	}
+	return child.n != prev.n+1
+}
+
+// compactKey formats key for the compact form, quoting it if needed.
+func compactKey[T comparable](key T) string {
+	s := fmt.Sprint(key)
+	if s == "" || strings.ContainsAny(s, " \t\n\r()[]\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// writeCompact writes the compact form of the tree rooted at k, down to
+// maxDepth, or the whole tree if maxDepth is negative.
+func (k *Node[T]) writeCompact(w io.Writer, maxDepth int) {
+	if k.firstChild == nil {
+		io.WriteString(w, compactKey(k.key))
+		return
+	}
+
+	io.WriteString(w, "(")
+	io.WriteString(w, compactKey(k.key))
+	if maxDepth == 0 {
+		io.WriteString(w, " ...)")
+		return
+	}
+
+	var prev *Node[T]
+	for child := k.firstChild; child != nil; child = child.nextSibling {
+		io.WriteString(w, " ")
+		if sparse(prev, child) {
+			fmt.Fprintf(w, "[%d]", child.n)
+		}
+		child.writeCompact(w, maxDepth-1)
+		prev = child
+	}
+	io.WriteString(w, ")")
+}
+
+// writeTree writes an indented rendering of the tree rooted at k, down
+// to maxDepth, or the whole tree if maxDepth is negative. If verbose is
+// set, every child index and bounded arity is shown.
+func (k *Node[T]) writeTree(w io.Writer, maxDepth int, verbose bool) {
+	k.writeTreeLine(w, verbose, false)
+	k.writeTreeChildren(w, "", maxDepth, verbose)
+}
+
+func (k *Node[T]) writeTreeLine(w io.Writer, verbose, showIndex bool) {
+	if showIndex {
+		fmt.Fprintf(w, "[%d] ", k.n)
+	}
+	fmt.Fprint(w, k.key)
+	if verbose && k.k != 0 {
+		fmt.Fprintf(w, " (k=%d)", k.k)
+	}
+	io.WriteString(w, "\n")
+}
+
+func (k *Node[T]) writeTreeChildren(w io.Writer, indent string, maxDepth int, verbose bool) {
+	if k.firstChild == nil {
+		return
+	}
+	if maxDepth == 0 {
+		io.WriteString(w, indent+"└── ...\n")
+		return
+	}
+
+	var prev *Node[T]
+	for child := k.firstChild; child != nil; child = child.nextSibling {
+		branch, next := "├── ", "│   "
+		if child.nextSibling == nil {
+			branch, next = "└── ", "    "
+		}
+		io.WriteString(w, indent+branch)
+		child.writeTreeLine(w, verbose, verbose || sparse(prev, child))
+		child.writeTreeChildren(w, indent+next, maxDepth-1, verbose)
+		prev = child
+	}
+}
@@ -0,0 +1,96 @@
+package karytree_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestFormatCompact(t *testing.T) {
+	a := constructDOTTree()
+
+	for _, tc := range []struct {
+		format   string
+		expected string
+	}{
+		{"%v", "(a [1](b [9]d) [4]c)"},
+		{"%s", "(a [1](b [9]d) [4]c)"},
+		{"%.1v", "(a [1](b ...) [4]c)"},
+		{"%.0v", "(a ...)"},
+	} {
+		if got := fmt.Sprintf(tc.format, a); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.format, tc.expected, got)
+		}
+	}
+
+	if a.String() != "(a [1](b [9]d) [4]c)" {
+		t.Errorf("expected String to match %%v, got %s", a.String())
+	}
+}
+
+func TestFormatCompactDense(t *testing.T) {
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("hello world")
+	c := karytree.NewNode("")
+	d := karytree.NewNode("(d)")
+	a.SetNthChild(0, &b)
+	a.SetNthChild(1, &c)
+	a.SetNthChild(2, &d)
+
+	expected := `(a "hello world" "" "(d)")`
+	if a.String() != expected {
+		t.Errorf("expected %s, got %s", expected, a.String())
+	}
+
+	leaf := karytree.NewNode(42)
+	if leaf.String() != "42" {
+		t.Errorf("expected a leaf to print as its key, got %s", leaf.String())
+	}
+
+	var nilNode *karytree.Node[int]
+	if fmt.Sprint(nilNode) != "<nil>" || nilNode.String() != "<nil>" {
+		t.Errorf("expected a nil node to print as <nil>")
+	}
+}
+
+func TestFormatTree(t *testing.T) {
+	a := karytree.NewKaryNode("a", 8)
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	e := karytree.NewNode("e")
+	a.SetNthChild(0, &b)
+	a.SetNthChild(5, &c)
+	b.SetNthChild(0, &d)
+	b.SetNthChild(1, &e)
+
+	expected := `a
+├── b
+│   ├── d
+│   └── e
+└── [5] c
+`
+	if got := fmt.Sprintf("%#v", &a); got != expected {
+		t.Errorf("unexpected tree:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	expected = `a (k=8)
+├── [0] b
+│   ├── [0] d
+│   └── [1] e
+└── [5] c
+`
+	if got := fmt.Sprintf("%+v", &a); got != expected {
+		t.Errorf("unexpected verbose tree:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	expected = `a
+├── b
+│   └── ...
+└── [5] c
+`
+	if got := fmt.Sprintf("%#.1v", &a); got != expected {
+		t.Errorf("unexpected depth-limited tree:\n%s\nexpected:\n%s", got, expected)
+	}
+}
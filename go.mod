@@ -1,10 +1,11 @@
 module github.com/sevagh/k-ary-tree
 
-go 1.20
+go 1.23
 
 require (
 	github.com/flyingmutant/rapid v0.0.0-20190904072629-5761511f78c8
 	github.com/google/gofuzz v1.0.0
+	go.uber.org/goleak v1.3.0
 )
 
 require (
@@ -0,0 +1,95 @@
+package karytree
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler. The tree rooted at
+// k is flattened into its Records in preorder, which are encoded with
+// encoding/gob, so the key type T must be encodable by gob. As with
+// MarshalJSON, only existing children are encoded, each with its own n,
+// so sparse trees round-trip exactly.
+func (k *Node[T]) MarshalBinary() ([]byte, error) {
+	records := []Record[T]{}
+	var flatten func(*Node[T])
+	flatten = func(node *Node[T]) {
+		records = append(records, Record[T]{
+			Key:         node.key,
+			N:           node.n,
+			K:           node.k,
+			NumChildren: node.NumChildren(),
+		})
+		for child := node.firstChild; child != nil; child = child.nextSibling {
+			flatten(child)
+		}
+	}
+	flatten(k)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, decoding the
+// format written by MarshalBinary. Like UnmarshalJSON, the key, arity
+// and children of k are replaced, the child index n is only restored if
+// k is a root, and the layout of k carries over to the decoded tree. k
+// is left untouched if data is invalid.
+func (k *Node[T]) UnmarshalBinary(data []byte) error {
+	var records []Record[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&records); err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%w: no nodes", ErrMalformed)
+	}
+
+	root := NewKaryNode(records[0].Key, records[0].K)
+	root.n = records[0].N
+	if records[0].NumChildren < 0 {
+		return fmt.Errorf("%w: negative number of children", ErrMalformed)
+	}
+	stack := popDone([]frame{{remaining: records[0].NumChildren, k: root.k}})
+	parents := []*Node[T]{&root}[:len(stack)]
+
+	for _, r := range records[1:] {
+		if len(stack) == 0 {
+			return fmt.Errorf("%w: trailing nodes", ErrMalformed)
+		}
+		if r.NumChildren < 0 {
+			return fmt.Errorf("%w: negative number of children", ErrMalformed)
+		}
+		if err := pushChild(stack, r.N); err != nil {
+			return err
+		}
+
+		depth := len(stack)
+		node := NewKaryNode(r.Key, r.K)
+		parents[depth-1].setNthChild(r.N, &node)
+		parents = append(parents[:depth], &node)
+
+		stack = popDone(append(stack, frame{remaining: r.NumChildren, k: r.K}))
+		parents = parents[:len(stack)]
+	}
+	if len(stack) > 0 {
+		return fmt.Errorf("%w: truncated tree", ErrMalformed)
+	}
+
+	k.replace(&root)
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder, so that nodes can be sent with
+// encoding/gob and net/rpc. It is the same as MarshalBinary.
+func (k *Node[T]) GobEncode() ([]byte, error) {
+	return k.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder. It is the same as UnmarshalBinary.
+func (k *Node[T]) GobDecode(data []byte) error {
+	return k.UnmarshalBinary(data)
+}
@@ -0,0 +1,129 @@
+package karytree_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestBinaryRoundTripSparse(t *testing.T) {
+	a := constructSparseUintTree()
+
+	data, err := a.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded karytree.Node[uint]
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if !karytree.Equals(a, &decoded) {
+		t.Errorf("decoded tree doesn't equal the original")
+	}
+	if decoded.K() != 32 || decoded.NthChild(17).NthChild(0).K() != 0 {
+		t.Errorf("arity wasn't preserved")
+	}
+	if decoded.NthChild(3).NthChild(31).Parent() != decoded.NthChild(3) {
+		t.Errorf("parent links weren't restored")
+	}
+	if decoded.NthChild(17).Parent() != &decoded {
+		t.Errorf("root children should point to the decoded node")
+	}
+}
+
+func TestGobEncodesNodes(t *testing.T) {
+	type cached struct {
+		Name string
+		Tree *karytree.Node[string]
+	}
+
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	a.SetNthChild(4, &c)
+	a.SetNthChild(1, &b)
+	b.SetNthChild(9, &d)
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(cached{Name: "tree", Tree: &a}); err != nil {
+		t.Fatalf("gob encode failed: %v", err)
+	}
+
+	var decoded cached
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf("gob decode failed: %v", err)
+	}
+
+	if decoded.Name != "tree" || !karytree.Equals(&a, decoded.Tree) {
+		t.Errorf("gob didn't round-trip the tree")
+	}
+}
+
+func TestUnmarshalBinaryReplacesChildren(t *testing.T) {
+	x := karytree.NewNode("x")
+	y := karytree.NewNode("y")
+	x.SetNthChild(1, &y)
+	data, err := x.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	a.SetNthChild(4, &b)
+
+	if err := a.UnmarshalBinary(data); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if a.Key() != "x" || a.NthChild(4) != nil || a.NthChild(1).Key() != "y" {
+		t.Errorf("expected existing children to be replaced")
+	}
+	if b.Parent() != nil {
+		t.Errorf("expected replaced children to be detached")
+	}
+}
+
+func TestUnmarshalBinaryErrors(t *testing.T) {
+	encode := func(records []karytree.Record[string]) []byte {
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(records); err != nil {
+			t.Fatalf("gob encode failed: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	a.SetNthChild(2, &b)
+
+	for _, tc := range []struct {
+		name    string
+		records []karytree.Record[string]
+		err     error
+	}{
+		{"empty", []karytree.Record[string]{}, karytree.ErrMalformed},
+		{"truncated", []karytree.Record[string]{{Key: "x", NumChildren: 2}, {Key: "y"}}, karytree.ErrMalformed},
+		{"trailing", []karytree.Record[string]{{Key: "x"}, {Key: "y"}}, karytree.ErrMalformed},
+		{"unsorted", []karytree.Record[string]{{Key: "x", NumChildren: 2}, {Key: "y", N: 3}, {Key: "z", N: 1}}, karytree.ErrMalformed},
+		{"out of range", []karytree.Record[string]{{Key: "x", K: 2, NumChildren: 1}, {Key: "y", N: 2}}, karytree.ErrIndexOutOfRange},
+	} {
+		if err := a.UnmarshalBinary(encode(tc.records)); !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.err, err)
+		}
+	}
+
+	if err := a.UnmarshalBinary([]byte("garbage")); err == nil {
+		t.Errorf("expected an error for invalid gob data")
+	}
+
+	if a.Key() != "a" || a.NthChild(2) != &b {
+		t.Errorf("expected a to be untouched by failed unmarshals")
+	}
+}
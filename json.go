@@ -0,0 +1,90 @@
+package karytree
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonNode mirrors Node with exported fields for encoding/json.
+type jsonNode[T comparable] struct {
+	Key      T          `json:"key"`
+	N        uint       `json:"n"`
+	K        uint       `json:"k,omitempty"`
+	Children []*Node[T] `json:"children,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. A node is encoded as an object
+// with its key, its child index n, its arity k if it is bounded, and its
+// children in ascending order of their index. Only the children that
+// exist are encoded, each with its own n, so sparse trees round-trip
+// exactly.
+func (k *Node[T]) MarshalJSON() ([]byte, error) {
+	jn := jsonNode[T]{
+		Key: k.key,
+		N:   k.n,
+		K:   k.k,
+	}
+	for curr := k.firstChild; curr != nil; curr = curr.nextSibling {
+		jn.Children = append(jn.Children, curr)
+	}
+	return json.Marshal(jn)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, decoding the format written
+// by MarshalJSON. The key, arity and children of k are replaced; any
+// existing children are detached. It is meant to be used on a root: the
+// child index n is restored, but k isn't moved within a parent. The
+// layout of k carries over to the decoded tree: if k uses ChildArray, so
+// does every bounded node below it, and otherwise none of them do.
+//
+// Duplicate child indices are an error, as are child indices that are
+// out of range for a bounded node. k is left untouched if data is
+// invalid.
+func (k *Node[T]) UnmarshalJSON(data []byte) error {
+	var jn jsonNode[T]
+	if err := json.Unmarshal(data, &jn); err != nil {
+		return err
+	}
+
+	root := NewKaryNode(jn.Key, jn.K)
+	root.n = jn.N
+	for _, child := range jn.Children {
+		if child == nil {
+			return fmt.Errorf("karytree: null child in JSON")
+		}
+		n := child.n
+		if root.NthChild(n) != nil {
+			return fmt.Errorf("karytree: duplicate child index %d in JSON", n)
+		}
+		if _, err := root.TrySetNthChild(n, child); err != nil {
+			return err
+		}
+	}
+
+	k.replace(&root)
+	return nil
+}
+
+// replace gives k the key, arity and children of root, a decoded tree
+// which is discarded, and detaches the former children of k. The child
+// index of root is only taken if k is a root, and the layout of k is
+// applied to the whole tree.
+func (k *Node[T]) replace(root *Node[T]) {
+	for k.firstChild != nil {
+		k.RemoveNthChild(k.firstChild.n)
+	}
+
+	k.key = root.key
+	k.k = root.k
+	k.invalidate()
+	if k.parent == nil {
+		k.n = root.n
+	}
+	k.firstChild = root.firstChild
+	for child := k.firstChild; child != nil; child = child.nextSibling {
+		child.parent = k
+	}
+	if k.dense() != nil {
+		SetLayout(k, ChildArray)
+	}
+}
@@ -0,0 +1,136 @@
+package karytree_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestJSONRoundTripSparse(t *testing.T) {
+	a := karytree.NewKaryNode("a", 32)
+	b := karytree.NewKaryNode("b", 32)
+	c := karytree.NewKaryNode("c", 32)
+	d := karytree.NewKaryNode("d", 32)
+
+	a.SetNthChild(17, &b)
+	a.SetNthChild(3, &c)
+	c.SetNthChild(31, &d)
+
+	data, err := json.Marshal(&a)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	expected := `{"key":"a","n":0,"k":32,"children":[` +
+		`{"key":"c","n":3,"k":32,"children":[{"key":"d","n":31,"k":32}]},` +
+		`{"key":"b","n":17,"k":32}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON:\n%s\nexpected:\n%s", data, expected)
+	}
+
+	var decoded karytree.Node[string]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if !karytree.Equals(&a, &decoded) {
+		t.Errorf("decoded tree doesn't equal the original")
+	}
+	if decoded.K() != 32 || decoded.NthChild(3).NthChild(31).K() != 32 {
+		t.Errorf("arity wasn't preserved")
+	}
+	if decoded.NthChild(3).NthChild(31).Parent() != decoded.NthChild(3) {
+		t.Errorf("parent links weren't restored")
+	}
+}
+
+func TestJSONRoundTripLarge(t *testing.T) {
+	tree := karytree.NewNode(0)
+	key := 1
+	for node := range karytree.BFSSeq(&tree) {
+		if node.Depth() == 3 {
+			break
+		}
+		for i := uint(0); i < 8; i += 3 {
+			child := karytree.NewNode(key)
+			key++
+			node.SetNthChild(i, &child)
+		}
+	}
+
+	data, err := json.Marshal(&tree)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded karytree.Node[int]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if !karytree.Equals(&tree, &decoded) {
+		t.Errorf("decoded tree doesn't equal the original")
+	}
+}
+
+func TestJSONUnmarshalReplacesChildren(t *testing.T) {
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	a.SetNthChild(4, &b)
+
+	if err := json.Unmarshal([]byte(`{"key":"x","n":0,"children":[{"key":"y","n":1}]}`), &a); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	if a.Key() != "x" || a.NthChild(4) != nil || a.NthChild(1).Key() != "y" {
+		t.Errorf("expected existing children to be replaced")
+	}
+	if b.Parent() != nil {
+		t.Errorf("expected replaced children to be detached")
+	}
+}
+
+func TestJSONUnmarshalErrors(t *testing.T) {
+	var a karytree.Node[string]
+
+	err := json.Unmarshal([]byte(`{"key":"a","n":0,"k":2,"children":[{"key":"b","n":7}]}`), &a)
+	if !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	err = json.Unmarshal([]byte(`{"key":"a","n":0,"children":[{"key":"b","n":1},{"key":"c","n":1}]}`), &a)
+	if err == nil {
+		t.Errorf("expected an error for duplicate child indices")
+	}
+
+	err = json.Unmarshal([]byte(`{"key":"a","n":0,"children":[null]}`), &a)
+	if err == nil {
+		t.Errorf("expected an error for a null child")
+	}
+
+	err = json.Unmarshal([]byte(`{"key":1}`), &a)
+	if err == nil {
+		t.Errorf("expected an error for a mistyped key")
+	}
+}
+
+func TestJSONUnmarshalErrorKeepsNode(t *testing.T) {
+	r := karytree.NewKaryNode("r", 3)
+	a := karytree.NewNode("a")
+	r.SetNthChild(1, &a)
+
+	for _, data := range []string{
+		`{"key":"x","n":0,"k":2,"children":[{"key":"b","n":7}]}`,
+		`{"key":"x","n":0,"children":[{"key":"b","n":1},{"key":"c","n":1}]}`,
+		`{"key":"x","n":0,"children":[{"key":"b","n":1},null]}`,
+	} {
+		if err := json.Unmarshal([]byte(data), &r); err == nil {
+			t.Fatalf("%s: expected an error", data)
+		}
+		if r.Key() != "r" || r.K() != 3 || r.NthChild(1) != &a || a.Parent() != &r {
+			t.Errorf("%s: expected the node to be untouched, got %v", data, &r)
+		}
+	}
+}
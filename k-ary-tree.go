@@ -1,50 +1,147 @@
 /*
 Package karytree implements a recursive k-ary tree data structure.
 
-The children of the tree is a slice of Node pointers.
-
-The slice of children is originally uninitialized, but is filled
-with k nils when any one child is set.
-
-The tree also stores the value of k. The caller is responsible
-for in-range indexing (e.g. don't set a 5th child of a k=3 node).
+The children of a node are stored as a sorted linked list of siblings,
+starting at its first child. Each child records its index n.
+
+A node created with NewNode is unbounded, and the caller is responsible
+for in-range indexing. A node created with NewKaryNode (or Binary) also
+stores the value of k, and rejects child indices n >= k with
+ErrIndexOutOfRange.
+Every child also points back at its parent, so a node must not be
+copied once it has children: the children of the copy would still
+point at the original. The constructors return nodes by value so that
+they can be allocated in place, but trees are built and passed around
+by pointer, and copied with Clone.
 */
 package karytree
 
-/*
-Package karytree implements a recursive k-ary tree data structure.
-
-The children of the tree is a slice of Node pointers.
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
 
-The slice of children is originally uninitialized, but is filled
-with k nils when any one child is set.
-
-The tree also stores the value of k. The caller is responsible
-for in-range indexing (e.g. don't set a 5th child of a k=3 node).
-*/
+// ErrIndexOutOfRange is returned when a child index n >= k is used on a
+// node with a bounded arity k.
+var ErrIndexOutOfRange = errors.New("karytree: child index out of range")
 
 // A Node is a typical recursive tree node, and it represents a tree
 // when it's traversed. The key is for data stored in the node.
+//
+// A Node must not be copied once it has children, since their parent
+// links hold its address. Use a *Node, and Clone to copy a tree.
 type Node[T comparable] struct {
 	key         T
 	n           uint
 	firstChild  *Node[T]
 	nextSibling *Node[T]
+	parent      *Node[T]
+	k           uint
+	ext         *nodeExt[T]
+}
+
+// nodeExt holds the state that only some nodes need, so that the others
+// only pay for one pointer: the digest cached by a Hasher, and the child
+// array of the ChildArray layout. It is freed when both are unset.
+type nodeExt[T comparable] struct {
+	digest cachedDigest[T]
+	dense  *denseChildren[T]
 }
 
-// NewNode creates a new node data key.
+// extension gets the nodeExt of k, allocating it if needed.
+func (k *Node[T]) extension() *nodeExt[T] {
+	if k.ext == nil {
+		k.ext = &nodeExt[T]{}
+	}
+	return k.ext
+}
+
+// trimExtension frees the nodeExt of k if it holds nothing.
+func (k *Node[T]) trimExtension() {
+	if k.ext != nil && k.ext.digest.hasher == nil && k.ext.dense == nil {
+		k.ext = nil
+	}
+}
+
+// NewNode creates a new node data key. Its arity is unbounded.
+//
+// The node is returned by value, so take its address before giving it
+// children, and use that pointer from then on: a copy of a node with
+// children leaves their parent links pointing at the original.
 func NewNode[T comparable](key T) Node[T] {
 	n := Node[T]{}
 	n.key = key
 	return n
 }
 
+// NewKaryNode creates a new node with data key which accepts at most
+// k children, at indices 0 to k-1. A k of 0 means unbounded, like NewNode.
+// Like NewNode, it returns a node that must not be copied once it has
+// children.
+func NewKaryNode[T comparable](key T, k uint) Node[T] {
+	n := NewNode(key)
+	n.k = k
+	return n
+}
+
+// K gets the arity of a node, or 0 if it is unbounded.
+func (k *Node[T]) K() uint {
+	return k.k
+}
+
+// checkIndex returns ErrIndexOutOfRange if n is not a valid child
+// index for k.
+func (k *Node[T]) checkIndex(n uint) error {
+	if k.k != 0 && n >= k.k {
+		return fmt.Errorf("%w: %d >= k=%d", ErrIndexOutOfRange, n, k.k)
+	}
+	return nil
+}
+
+// TrySetNthChild is like SetNthChild, but returns ErrIndexOutOfRange
+// instead of panicking when n is out of range for a bounded node.
+func (k *Node[T]) TrySetNthChild(n uint, other *Node[T]) (*Node[T], error) {
+	if err := k.checkIndex(n); err != nil {
+		return nil, err
+	}
+	return k.setNthChild(n, other), nil
+}
+
+// TryNthChild is like NthChild, but returns ErrIndexOutOfRange when n is
+// out of range for a bounded node.
+func (k *Node[T]) TryNthChild(n uint) (*Node[T], error) {
+	if err := k.checkIndex(n); err != nil {
+		return nil, err
+	}
+	return k.NthChild(n), nil
+}
+
 // SetNthChild sets the Nth child. If an existing node is replaced,
-// that node is returned.
+// that node is returned, fully unlinked from k. If other is already
+// attached somewhere else in a tree, it is detached first.
+//
+// SetNthChild panics with ErrIndexOutOfRange if k is bounded and n is out
+// of range; use TrySetNthChild to get the error instead.
 func (k *Node[T]) SetNthChild(n uint, other *Node[T]) *Node[T] {
+	if err := k.checkIndex(n); err != nil {
+		panic(err)
+	}
+	return k.setNthChild(n, other)
+}
+
+func (k *Node[T]) setNthChild(n uint, other *Node[T]) *Node[T] {
+	other.Detach()
 	other.n = n
+	other.parent = k
+	k.invalidate()
+
+	if k.dense() != nil {
+		return k.setDenseChild(n, other)
+	}
 
 	if k.firstChild == nil {
+		other.nextSibling = nil
 		k.firstChild = other
 		return nil
 	}
@@ -54,6 +151,7 @@ func (k *Node[T]) SetNthChild(n uint, other *Node[T]) *Node[T] {
 		ret := k.firstChild
 		other.nextSibling = k.firstChild.nextSibling
 		k.firstChild = other
+		ret.unlink()
 		return ret
 	} else if k.firstChild.n > n {
 		// relink
@@ -65,6 +163,7 @@ func (k *Node[T]) SetNthChild(n uint, other *Node[T]) *Node[T] {
 	curr := k.firstChild
 	for {
 		if curr.nextSibling == nil {
+			other.nextSibling = nil
 			curr.nextSibling = other
 			return nil
 		}
@@ -77,8 +176,8 @@ func (k *Node[T]) SetNthChild(n uint, other *Node[T]) *Node[T] {
 			 * curr -> other -> ..., return nextSibling
 			 */
 			other.nextSibling = curr.nextSibling.nextSibling
-			curr.nextSibling.nextSibling = nil // wipe the rest of the links from the evicted node
 			ret := curr.nextSibling
+			ret.unlink() // wipe the rest of the links from the evicted node
 			curr.nextSibling = other
 			return ret
 		} else if curr.nextSibling.n > n {
@@ -97,8 +196,95 @@ func (k *Node[T]) SetNthChild(n uint, other *Node[T]) *Node[T] {
 	}
 }
 
-// NthChild gets the Nth child.
+// RemoveNthChild removes the Nth child and returns it, fully unlinked
+// from k. If there is no Nth child, nil is returned.
+func (k *Node[T]) RemoveNthChild(n uint) *Node[T] {
+	if k.dense() != nil {
+		return k.removeDenseChild(n)
+	}
+
+	if k.firstChild == nil || k.firstChild.n > n {
+		return nil
+	}
+
+	if k.firstChild.n == n {
+		ret := k.firstChild
+		k.firstChild = ret.nextSibling
+		ret.unlink()
+		k.invalidate()
+		return ret
+	}
+
+	curr := k.firstChild
+	for curr.nextSibling != nil {
+		if curr.nextSibling.n == n {
+			/* curr -> nextSibling -> ...
+			 *
+			 * curr -> ..., return nextSibling
+			 */
+			ret := curr.nextSibling
+			curr.nextSibling = ret.nextSibling
+			ret.unlink()
+			k.invalidate()
+			return ret
+		} else if curr.nextSibling.n > n {
+			// overshoot, nth child doesn't exist
+			return nil
+		}
+		curr = curr.nextSibling
+	}
+
+	return nil
+}
+
+// Detach removes k from its parent's children, leaving k as the root
+// of its own tree. Detaching a root does nothing.
+func (k *Node[T]) Detach() {
+	if k.parent != nil {
+		k.parent.RemoveNthChild(k.n)
+	}
+}
+
+// ReplaceSubtree puts other in place of k in k's parent, at k's child
+// index. k is left fully unlinked. Replacing a root does nothing.
+func (k *Node[T]) ReplaceSubtree(other *Node[T]) {
+	if k.parent == nil || k == other {
+		return
+	}
+	k.parent.setNthChild(k.n, other)
+}
+
+// MoveChild moves the child at index from to index to, keeping its
+// subtree. If another child already occupies index to, it is evicted
+// and returned like in SetNthChild. If there is no child at index from,
+// nothing happens and nil is returned.
+//
+// Like SetNthChild, MoveChild panics with ErrIndexOutOfRange if k is
+// bounded and to is out of range.
+func (k *Node[T]) MoveChild(from, to uint) *Node[T] {
+	if err := k.checkIndex(to); err != nil {
+		panic(err)
+	}
+	child := k.RemoveNthChild(from)
+	if child == nil {
+		return nil
+	}
+	return k.setNthChild(to, child)
+}
+
+// unlink wipes the links that tie k to its parent and siblings, but
+// keeps its own children.
+func (k *Node[T]) unlink() {
+	k.parent = nil
+	k.nextSibling = nil
+}
+
+// NthChild gets the Nth child. It is O(1) with the ChildArray layout.
 func (k *Node[T]) NthChild(n uint) *Node[T] {
+	if d := k.dense(); d != nil {
+		return d.get(n)
+	}
+
 	curr := k.firstChild
 	for curr != nil {
 		if curr.n == n {
@@ -115,6 +301,116 @@ func (k *Node[T]) NthChild(n uint) *Node[T] {
 	return nil
 }
 
+// Children returns an iterator over the children of k in ascending
+// order of their child index, yielding each index with its child. The
+// yielded child may be removed, detached or given to another parent
+// during the iteration, which goes on with its next sibling; other
+// changes to the children of k may leave the iteration on a node that
+// isn't a child anymore, or yield a child twice.
+func (k *Node[T]) Children() iter.Seq2[uint, *Node[T]] {
+	return func(yield func(uint, *Node[T]) bool) {
+		for curr := k.firstChild; curr != nil; {
+			next := curr.nextSibling
+			if !yield(curr.n, curr) {
+				return
+			}
+			curr = next
+		}
+	}
+}
+
+// NumChildren counts the children of k.
+func (k *Node[T]) NumChildren() int {
+	count := 0
+	for curr := k.firstChild; curr != nil; curr = curr.nextSibling {
+		count++
+	}
+	return count
+}
+
+// IsLeaf reports whether k has no children.
+func (k *Node[T]) IsLeaf() bool {
+	return k.firstChild == nil
+}
+
+// FirstChild gets the child of k with the lowest index.
+func (k *Node[T]) FirstChild() *Node[T] {
+	return k.firstChild
+}
+
+// LastChild gets the child of k with the highest index.
+func (k *Node[T]) LastChild() *Node[T] {
+	curr := k.firstChild
+	for curr != nil && curr.nextSibling != nil {
+		curr = curr.nextSibling
+	}
+	return curr
+}
+
+// NextSibling gets the sibling of k with the next highest index.
+func (k *Node[T]) NextSibling() *Node[T] {
+	return k.nextSibling
+}
+
+// PrevSibling gets the sibling of k with the next lowest index. Since
+// the sibling list is singly linked, this walks it from the parent.
+func (k *Node[T]) PrevSibling() *Node[T] {
+	if k.parent == nil {
+		return nil
+	}
+
+	var prev *Node[T]
+	for curr := k.parent.firstChild; curr != nil && curr != k; curr = curr.nextSibling {
+		prev = curr
+	}
+	return prev
+}
+
+// ChildIndex gets the index n at which k is a child of its parent.
+func (k *Node[T]) ChildIndex() uint {
+	return k.n
+}
+
+// Parent gets the node that k is a child of, or nil if k is a root.
+func (k *Node[T]) Parent() *Node[T] {
+	return k.parent
+}
+
+// Ancestors returns an iterator over the ancestors of k, starting
+// with its parent and ending with the root.
+func (k *Node[T]) Ancestors() iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		for curr := k.parent; curr != nil; curr = curr.parent {
+			if !yield(curr) {
+				return
+			}
+		}
+	}
+}
+
+// Depth is the number of edges between k and its root. A root
+// has a depth of 0.
+func (k *Node[T]) Depth() int {
+	depth := 0
+	for curr := k.parent; curr != nil; curr = curr.parent {
+		depth++
+	}
+	return depth
+}
+
+// PathFromRoot returns the child indices that lead from the root
+// down to k, such that following NthChild(path[i]) from the root
+// reaches k. A root has an empty path.
+func (k *Node[T]) PathFromRoot() []uint {
+	path := make([]uint, k.Depth())
+	i := len(path) - 1
+	for curr := k; curr.parent != nil; curr = curr.parent {
+		path[i] = curr.n
+		i--
+	}
+	return path
+}
+
 // Key gets the data stored in a node
 func (k *Node[T]) Key() T {
 	return k.key
@@ -123,12 +419,17 @@ func (k *Node[T]) Key() T {
 // SetKey modifies the data in a node
 func (k *Node[T]) SetKey(newKey T) {
 	k.key = newKey
+	k.invalidate()
 }
 
 // BFS is a channel-based BFS for tree nodes.
 // Channels are used similar to Python generators.
 // Inspired by https://blog.carlmjohnson.net/post/on-using-go-channels-like-python-generators/
 // Examples of how to use it can be seen in algorithms_test.go
+//
+// If quit is never closed and the caller stops reading early, the
+// goroutine leaks. Prefer BFSSeq, or BFSContext which stops on
+// cancellation and reports ctx.Err().
 func BFS[T comparable](root *Node[T], quit <-chan struct{}) <-chan *Node[T] {
 	nChan := make(chan *(Node[T]))
 
@@ -157,8 +458,47 @@ func BFS[T comparable](root *Node[T], quit <-chan struct{}) <-chan *Node[T] {
 	return nChan
 }
 
+// All returns an iterator over the tree rooted at k in BFS order.
+// It is equivalent to BFSSeq(k).
+func (k *Node[T]) All() iter.Seq[*Node[T]] {
+	return BFSSeq(k)
+}
+
+// BFSSeq is an iterator-based BFS for tree nodes. Unlike BFS it runs
+// synchronously in the caller's goroutine, so breaking out of the range
+// loop stops the traversal without leaking anything.
+func BFSSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		if root == nil {
+			return
+		}
+
+		queue := [](*Node[T]){root}
+		var curr *Node[T]
+
+		for len(queue) > 0 {
+			curr, queue = queue[0], queue[1:]
+
+			if !yield(curr) {
+				return
+			}
+
+			next := curr.firstChild
+			for next != nil {
+				queue = append(queue, next)
+				next = next.nextSibling
+			}
+		}
+	}
+}
+
 // Equals does a deep comparison of two tree nodes. The only special
 // behavior is that two nils are considered "equal trees."
+//
+// Equals never uses the digests cached by a Hasher, since a stale or
+// colliding digest would make it wrong; it always compares the whole
+// trees. Hasher.Equal returns early on different cached digests, for
+// callers who can trust them.
 func Equals[T comparable](a, b *Node[T]) bool {
 	if a == b {
 		return true
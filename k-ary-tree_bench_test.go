@@ -1,6 +1,11 @@
 package karytree_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"math/rand"
+	"slices"
 	"testing"
 
 	"github.com/sevagh/k-ary-tree"
@@ -10,12 +15,12 @@ func BenchmarkKaryTreeK2Sparse(b *testing.B) {
 	prevTree := karyTreeKSparseHelper(2)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKSparseHelper(2)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Sparse K=2 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -26,12 +31,12 @@ func BenchmarkKaryTreeK2VerySparse(b *testing.B) {
 	prevTree := karyTreeKVerySparseHelper(2)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKVerySparseHelper(2)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching VerySparse K=2 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -42,12 +47,12 @@ func BenchmarkKaryTreeK2Complete(b *testing.B) {
 	prevTree := karyTreeKCompleteHelper(2)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKCompleteHelper(2)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Complete K=2 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -58,12 +63,12 @@ func BenchmarkKaryTreeK8Sparse(b *testing.B) {
 	prevTree := karyTreeKSparseHelper(8)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKSparseHelper(8)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Sparse K=8 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -74,12 +79,12 @@ func BenchmarkKaryTreeK8VerySparse(b *testing.B) {
 	prevTree := karyTreeKVerySparseHelper(8)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKVerySparseHelper(8)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching VerySparse K=8 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -90,12 +95,12 @@ func BenchmarkKaryTreeK8Complete(b *testing.B) {
 	prevTree := karyTreeKCompleteHelper(8)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKCompleteHelper(8)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Complete K=8 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -106,12 +111,12 @@ func BenchmarkKaryTreeK32Sparse(b *testing.B) {
 	prevTree := karyTreeKSparseHelper(32)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKSparseHelper(32)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Sparse K=32 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -122,12 +127,12 @@ func BenchmarkKaryTreeK32VerySparse(b *testing.B) {
 	prevTree := karyTreeKVerySparseHelper(32)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKVerySparseHelper(32)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching VerySparse K=32 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
@@ -138,19 +143,271 @@ func BenchmarkKaryTreeK32Complete(b *testing.B) {
 	prevTree := karyTreeKCompleteHelper(32)
 
 	b.ResetTimer()
-	var tree karytree.Node[interface{}]
+	var tree *karytree.Node[interface{}]
 
 	for i := 0; i < b.N; i++ {
 		tree = karyTreeKCompleteHelper(32)
 
-		if !karytree.Equals(&tree, &prevTree) {
+		if !karytree.Equals(tree, prevTree) {
 			b.Errorf("Benching Complete K=32 small trees but I don't think they're identical...")
 		}
 		prevTree = tree
 	}
 }
 
-func karyTreeKSparseHelper(K int) karytree.Node[interface{}] {
+func BenchmarkForestK2Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(2)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(2, sparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=2 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK2VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(2)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(2, verySparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=2 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK2Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(2)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(2, completeK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=2 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK8Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(8)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(8, sparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=8 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK8VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(8)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(8, verySparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=8 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK8Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(8)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(8, completeK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=8 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK32Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(32)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(32, sparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=32 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK32VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(32)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(32, verySparseK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=32 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkForestK32Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(32)
+	f := karytree.NewForest[interface{}](0)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Reset()
+		tree := treeHelper(32, completeK, forestNode(f))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=32 forest trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK2Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(2, sparseK, denseNode(2))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=2 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK2VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(2, verySparseK, denseNode(2))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=2 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK2Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(2)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(2, completeK, denseNode(2))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=2 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK8Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(8)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(8, sparseK, denseNode(8))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=8 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK8VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(8)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(8, verySparseK, denseNode(8))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=8 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK8Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(8)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(8, completeK, denseNode(8))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=8 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK32Sparse(b *testing.B) {
+	prevTree := karyTreeKSparseHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(32, sparseK, denseNode(32))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Sparse K=32 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK32VerySparse(b *testing.B) {
+	prevTree := karyTreeKVerySparseHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(32, verySparseK, denseNode(32))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching VerySparse K=32 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func BenchmarkDenseK32Complete(b *testing.B) {
+	prevTree := karyTreeKCompleteHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree := treeHelper(32, completeK, denseNode(32))
+
+		if !karytree.Equals(tree, prevTree) {
+			b.Errorf("Benching Complete K=32 dense trees but I don't think they're identical...")
+		}
+	}
+}
+
+func karyTreeKSparseHelper(K int) *karytree.Node[interface{}] {
 	var tree karytree.Node[interface{}]
 
 	var key int
@@ -191,10 +448,55 @@ func karyTreeKSparseHelper(K int) karytree.Node[interface{}] {
 		}
 	}
 
+	return &tree
+}
+
+// treeHelper builds the trees of the karyTreeK*Helper benchmarks from
+// the nodes returned by newNode, keeping the children at depth 1, 2 and
+// 3 for which keep returns true. The keys are numbered in the same order
+// as those of the helpers.
+func treeHelper(K int, keep [3]func(uint) bool, newNode func(key int) *karytree.Node[interface{}]) *karytree.Node[interface{}] {
+	key := 0
+	next := func() *karytree.Node[interface{}] {
+		node := newNode(key)
+		key++
+		return node
+	}
+
+	tree := next()
+	for i := uint(0); i < uint(K); i++ {
+		if !keep[0](i) {
+			continue
+		}
+		child := next()
+		tree.SetNthChild(i, child)
+		for j := uint(0); j < uint(K); j++ {
+			if !keep[1](j) {
+				continue
+			}
+			grandchild := next()
+			child.SetNthChild(j, grandchild)
+			for k := uint(0); k < uint(K); k++ {
+				if keep[2](k) {
+					grandchild.SetNthChild(k, next())
+				}
+			}
+		}
+	}
 	return tree
 }
 
-func karyTreeKCompleteHelper(K int) karytree.Node[interface{}] {
+var (
+	keepAll     = func(uint) bool { return true }
+	keepEven    = func(i uint) bool { return i%2 == 0 }
+	keepOdd     = func(i uint) bool { return i%2 != 0 }
+	keepFirst   = func(i uint) bool { return i == 0 }
+	completeK   = [3]func(uint) bool{keepAll, keepAll, keepAll}
+	sparseK     = [3]func(uint) bool{keepEven, keepOdd, keepEven}
+	verySparseK = [3]func(uint) bool{keepFirst, keepFirst, keepFirst}
+)
+
+func karyTreeKCompleteHelper(K int) *karytree.Node[interface{}] {
 	var tree karytree.Node[interface{}]
 
 	var key int
@@ -225,10 +527,10 @@ func karyTreeKCompleteHelper(K int) karytree.Node[interface{}] {
 		}
 	}
 
-	return tree
+	return &tree
 }
 
-func karyTreeKVerySparseHelper(K int) karytree.Node[interface{}] {
+func karyTreeKVerySparseHelper(K int) *karytree.Node[interface{}] {
 	var tree karytree.Node[interface{}]
 
 	var key int
@@ -269,5 +571,241 @@ func karyTreeKVerySparseHelper(K int) karytree.Node[interface{}] {
 		}
 	}
 
-	return tree
+	return &tree
+}
+
+func BenchmarkBFSChanK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range karytree.BFS(tree, nil) {
+		}
+	}
+}
+
+func BenchmarkBFSSeqK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range karytree.BFSSeq(tree) {
+		}
+	}
+}
+
+// interfaceIntCodec encodes the int keys of the benchmark helper trees.
+type interfaceIntCodec struct{}
+
+func (interfaceIntCodec) AppendKey(dst []byte, key interface{}) ([]byte, error) {
+	return karytree.IntCodec[int]{}.AppendKey(dst, key.(int))
+}
+
+func (interfaceIntCodec) DecodeKey(src []byte) (interface{}, error) {
+	return karytree.IntCodec[int]{}.DecodeKey(src)
+}
+
+func BenchmarkEncoderK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+	var buf bytes.Buffer
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := karytree.NewEncoder[interface{}](&buf, interfaceIntCodec{}).Encode(tree); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := karytree.NewDecoder[interface{}](&buf, interfaceIntCodec{}).Decode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkJSONK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := json.Marshal(tree)
+		if err != nil {
+			b.Fatal(err)
+		}
+		var decoded karytree.Node[interface{}]
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNthChildK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for n := uint(0); n < 32; n++ {
+			if tree.NthChild(n).NthChild(31-n) == nil {
+				b.Fatal("missing child")
+			}
+		}
+	}
+}
+
+func BenchmarkCompleteTreeNthChildK32Complete(b *testing.B) {
+	tree := karyTreeKCompleteHelper(32)
+	c, err := karytree.CompleteFromNode(tree, 32)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for n := uint(0); n < 32; n++ {
+			if c.NthChild(c.NthChild(0, n), 31-n) < 0 {
+				b.Fatal("missing child")
+			}
+		}
+	}
+}
+
+func BenchmarkDenseNthChildK32Complete(b *testing.B) {
+	tree := treeHelper(32, completeK, denseNode(32))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for n := uint(0); n < 32; n++ {
+			if tree.NthChild(n).NthChild(31-n) == nil {
+				b.Fatal("missing child")
+			}
+		}
+	}
+}
+
+const btreeBenchSize = 10000
+
+func btreeBenchKeys() []int {
+	return rand.New(rand.NewSource(25)).Perm(btreeBenchSize)
+}
+
+func BenchmarkBTreeInsert(b *testing.B) {
+	keys := btreeBenchKeys()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		t := karytree.NewBTree[int, int](32)
+		for _, key := range keys {
+			t.Insert(key, key)
+		}
+	}
+}
+
+func BenchmarkBTreeLoad(b *testing.B) {
+	keys := slices.Sorted(slices.Values(btreeBenchKeys()))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := karytree.LoadBTree(32, func(yield func(int, int) bool) {
+			for _, key := range keys {
+				if !yield(key, key) {
+					return
+				}
+			}
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkMapSortInsert is the baseline of BenchmarkBTreeInsert and
+// BenchmarkBTreeLoad: a map, with its keys sorted once filled.
+func BenchmarkMapSortInsert(b *testing.B) {
+	keys := btreeBenchKeys()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m := map[int]int{}
+		for _, key := range keys {
+			m[key] = key
+		}
+		_ = slices.Sorted(maps.Keys(m))
+	}
+}
+
+func BenchmarkBTreeGet(b *testing.B) {
+	keys := btreeBenchKeys()
+	t := karytree.NewBTree[int, int](32)
+	for _, key := range keys {
+		t.Insert(key, key)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := t.Get(keys[i%len(keys)]); !ok {
+			b.Fatal("missing key")
+		}
+	}
+}
+
+func BenchmarkMapGet(b *testing.B) {
+	keys := btreeBenchKeys()
+	m := map[int]int{}
+	for _, key := range keys {
+		m[key] = key
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := m[keys[i%len(keys)]]; !ok {
+			b.Fatal("missing key")
+		}
+	}
+}
+
+func BenchmarkBTreeRange(b *testing.B) {
+	keys := btreeBenchKeys()
+	t := karytree.NewBTree[int, int](32)
+	for _, key := range keys {
+		t.Insert(key, key)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lo := keys[i%len(keys)]
+		count := 0
+		for range t.Range(lo, lo+100) {
+			count++
+		}
+		if count == 0 {
+			b.Fatal("empty range")
+		}
+	}
+}
+
+// BenchmarkMapSortRange is the baseline of BenchmarkBTreeRange: a map
+// has to sort its keys before every range scan, since it may have
+// changed in between.
+func BenchmarkMapSortRange(b *testing.B) {
+	keys := btreeBenchKeys()
+	m := map[int]int{}
+	for _, key := range keys {
+		m[key] = key
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		lo := keys[i%len(keys)]
+		sorted := slices.Sorted(maps.Keys(m))
+		count := 0
+		start, _ := slices.BinarySearch(sorted, lo)
+		for _, key := range sorted[start:] {
+			if key >= lo+100 {
+				break
+			}
+			_ = m[key]
+			count++
+		}
+		if count == 0 {
+			b.Fatal("empty range")
+		}
+	}
 }
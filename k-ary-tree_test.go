@@ -1,6 +1,7 @@
 package karytree_test
 
 import (
+	"errors"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -210,6 +211,11 @@ func (m *karytreeMachine) Get(t *rapid.T) {
 		t.Fatalf("got invalid value: %v vs expected %v", curr.Key(), currState)
 	}
 
+	gotPath := curr.PathFromRoot()
+	if len(gotPath) != len(currPath) || (len(gotPath) > 0 && !reflect.DeepEqual(gotPath, currPath)) {
+		t.Fatalf("got invalid path from root: %v vs expected %v", gotPath, currPath)
+	}
+
 	m.state = m.state[1:]
 	m.path = m.path[1:]
 }
@@ -314,7 +320,7 @@ func TestCompareTrees(t *testing.T) {
 	tree1 := constructTree(8)
 	tree2 := constructTree(8)
 
-	if !karytree.Equals(&tree1, &tree2) {
+	if !karytree.Equals(tree1, tree2) {
 		t.Errorf("expected identical trees to be equal")
 	}
 }
@@ -323,7 +329,7 @@ func TestCompareSparseTrees(t *testing.T) {
 	tree1 := constructTreeSparse(8)
 	tree2 := constructTreeSparse(8)
 
-	if !karytree.Equals(&tree1, &tree2) {
+	if !karytree.Equals(tree1, tree2) {
 		t.Errorf("expected identical trees to be equal")
 	}
 }
@@ -337,7 +343,7 @@ func TestEmptyTreesEqual(t *testing.T) {
 func TestOneNilEqual(t *testing.T) {
 	tree1 := constructTreeSparse(8)
 
-	if karytree.Equals(nil, &tree1) || karytree.Equals(&tree1, nil) {
+	if karytree.Equals(nil, tree1) || karytree.Equals(tree1, nil) {
 		t.Errorf("nil and real trees can't be equal")
 	}
 }
@@ -349,7 +355,7 @@ func TestNotEqualTrees(t *testing.T) {
 	rand := karytree.NewNode[interface{}]("hello world")
 	tree2.SetNthChild(3, &rand)
 
-	if karytree.Equals(&tree1, &tree2) {
+	if karytree.Equals(tree1, tree2) {
 		t.Errorf("tree1 and tree2 shouldn't be equal")
 	}
 }
@@ -394,7 +400,7 @@ func TestTreeInsertionSortedOrderNotEquals(t *testing.T) {
 	}
 }
 
-func constructTree(K int) karytree.Node[interface{}] {
+func constructTree(K int) *karytree.Node[interface{}] {
 	var key int
 	tree := karytree.NewNode[interface{}](key)
 	key++
@@ -421,10 +427,10 @@ func constructTree(K int) karytree.Node[interface{}] {
 		}
 	}
 
-	return tree
+	return &tree
 }
 
-func constructTreeSparse(K int) karytree.Node[interface{}] {
+func constructTreeSparse(K int) *karytree.Node[interface{}] {
 	var tree karytree.Node[interface{}]
 
 	var key int
@@ -465,5 +471,544 @@ func constructTreeSparse(K int) karytree.Node[interface{}] {
 		}
 	}
 
-	return tree
+	return &tree
+}
+
+func TestBFSSeq(t *testing.T) {
+	tree := constructTreeSparse(4)
+
+	expected := []*karytree.Node[interface{}]{}
+	for node := range karytree.BFS(tree, nil) {
+		expected = append(expected, node)
+	}
+
+	got := []*karytree.Node[interface{}]{}
+	for node := range karytree.BFSSeq(tree) {
+		got = append(got, node)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("BFSSeq order differs from BFS: %+v vs %+v", got, expected)
+	}
+
+	all := []*karytree.Node[interface{}]{}
+	for node := range tree.All() {
+		all = append(all, node)
+	}
+
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("All order differs from BFS: %+v vs %+v", all, expected)
+	}
+}
+
+func TestBFSSeqEarlyBreak(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(1, &c)
+
+	ctr := 0
+	for node := range karytree.BFSSeq(&a) {
+		if node.Key().(string) != "a" {
+			t.Errorf("expected node key 'a', got '%s'", node.Key())
+		}
+		ctr++
+		break
+	}
+
+	if ctr != 1 {
+		t.Errorf("expected exactly one node before break, got %d", ctr)
+	}
+}
+
+func TestBFSSeqNil(t *testing.T) {
+	for node := range karytree.BFSSeq[interface{}](nil) {
+		t.Errorf("expected no nodes from nil root, got %+v", node)
+	}
+}
+
+func TestParent(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+
+	a.SetNthChild(3, &b)
+	a.SetNthChild(1, &c)
+	c.SetNthChild(7, &d)
+
+	if a.Parent() != nil {
+		t.Errorf("expected root to have no parent, got %+v", a.Parent())
+	}
+	if b.Parent() != &a || c.Parent() != &a {
+		t.Errorf("expected a to be the parent of b and c")
+	}
+	if d.Parent() != &c {
+		t.Errorf("expected c to be the parent of d, got %+v", d.Parent())
+	}
+
+	ancestors := []*karytree.Node[interface{}]{}
+	for node := range d.Ancestors() {
+		ancestors = append(ancestors, node)
+	}
+	if !reflect.DeepEqual(ancestors, []*karytree.Node[interface{}]{&c, &a}) {
+		t.Errorf("expected ancestors of d to be [c a], got %+v", ancestors)
+	}
+
+	if a.Depth() != 0 || c.Depth() != 1 || d.Depth() != 2 {
+		t.Errorf("unexpected depths: a %d, c %d, d %d", a.Depth(), c.Depth(), d.Depth())
+	}
+
+	if path := d.PathFromRoot(); !reflect.DeepEqual(path, []uint{1, 7}) {
+		t.Errorf("expected path [1 7] to d, got %+v", path)
+	}
+	if path := a.PathFromRoot(); len(path) != 0 {
+		t.Errorf("expected empty path to root, got %+v", path)
+	}
+}
+
+// TestParentByValueRoot builds its tree with constructTree, whose root
+// comes from NewNode by value and is returned by pointer.
+func TestParentByValueRoot(t *testing.T) {
+	tree := constructTree(3)
+
+	for n, child := range tree.Children() {
+		if child.Parent() != tree {
+			t.Fatalf("expected the root to be the parent of child %d", n)
+		}
+		if path := child.PathFromRoot(); !reflect.DeepEqual(path, []uint{n}) {
+			t.Errorf("expected path [%d] to child %d, got %+v", n, n, path)
+		}
+		for _, grandchild := range child.Children() {
+			if grandchild.Parent() != child || grandchild.Depth() != 2 {
+				t.Errorf("expected grandchild %v under child %d", grandchild.Key(), n)
+			}
+		}
+	}
+	if tree.NthChild(2).PrevSibling() != tree.NthChild(1) {
+		t.Errorf("expected child 1 to be the previous sibling of child 2")
+	}
+}
+
+// TestFixtureParents checks that the trees built by the test helpers
+// are returned by pointer, with their parent links intact.
+func TestFixtureParents(t *testing.T) {
+	checkParents(t, "constructTree", constructTree(3))
+	checkParents(t, "constructTreeSparse", constructTreeSparse(3))
+	checkParents(t, "constructTernaryTree", constructTernaryTree())
+	checkParents(t, "constructDOTTree", constructDOTTree())
+	checkParents(t, "constructSparseUintTree", constructSparseUintTree())
+	checkParents(t, "constructBinaryTreeSeq", constructBinaryTreeSeq())
+	checkParents(t, "karyTreeKCompleteHelper", karyTreeKCompleteHelper(3))
+	checkParents(t, "karyTreeKSparseHelper", karyTreeKSparseHelper(3))
+	checkParents(t, "karyTreeKVerySparseHelper", karyTreeKVerySparseHelper(3))
+}
+
+func checkParents[T comparable](t *testing.T, name string, root *karytree.Node[T]) {
+	t.Helper()
+	if root.Parent() != nil {
+		t.Errorf("%s: expected the root to have no parent", name)
+	}
+	for node := range karytree.BFSSeq(root) {
+		for _, child := range node.Children() {
+			if child.Parent() != node {
+				t.Errorf("%s: expected %v to be the parent of %v", name, node.Key(), child.Key())
+			}
+		}
+	}
+}
+
+func TestEvictionClearsParent(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+	e := karytree.NewNode[interface{}]("e")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(2, &c)
+
+	// evict the first child
+	evicted := a.SetNthChild(0, &d)
+	if evicted != &b || b.Parent() != nil {
+		t.Errorf("expected evicted first child b to be detached, parent %+v", b.Parent())
+	}
+	if d.Parent() != &a {
+		t.Errorf("expected a to be the parent of d")
+	}
+
+	// evict a middle child
+	evicted = a.SetNthChild(2, &e)
+	if evicted != &c || c.Parent() != nil {
+		t.Errorf("expected evicted sibling c to be detached, parent %+v", c.Parent())
+	}
+	if e.Parent() != &a {
+		t.Errorf("expected a to be the parent of e")
+	}
+}
+
+func bfsKeys(root *karytree.Node[interface{}]) []interface{} {
+	keys := []interface{}{}
+	for node := range karytree.BFSSeq(root) {
+		keys = append(keys, node.Key())
+	}
+	return keys
+}
+
+func TestEvictedFirstChildIsUnlinked(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(1, &c)
+	evicted := a.SetNthChild(0, &d)
+
+	// the evicted node is now a root and mustn't reach its former siblings
+	if !reflect.DeepEqual(bfsKeys(evicted), []interface{}{"b"}) {
+		t.Errorf("evicted first child still linked to its siblings: %+v", bfsKeys(evicted))
+	}
+	if !reflect.DeepEqual(bfsKeys(&a), []interface{}{"a", "d", "c"}) {
+		t.Errorf("unexpected tree after eviction: %+v", bfsKeys(&a))
+	}
+}
+
+func TestRemoveNthChild(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+	e := karytree.NewNode[interface{}]("e")
+
+	a.SetNthChild(1, &b)
+	a.SetNthChild(4, &c)
+	a.SetNthChild(9, &d)
+	c.SetNthChild(0, &e)
+
+	if removed := a.RemoveNthChild(2); removed != nil {
+		t.Errorf("expected no 2nd child to remove, got %+v", removed)
+	}
+	if removed := a.RemoveNthChild(10); removed != nil {
+		t.Errorf("expected no 10th child to remove, got %+v", removed)
+	}
+
+	// middle of the sibling list, keeps its own subtree
+	removed := a.RemoveNthChild(4)
+	if removed != &c || c.Parent() != nil {
+		t.Errorf("expected c to be removed and detached")
+	}
+	if !reflect.DeepEqual(bfsKeys(removed), []interface{}{"c", "e"}) {
+		t.Errorf("removed node should keep only its subtree, got %+v", bfsKeys(removed))
+	}
+	if a.NthChild(4) != nil || a.NthChild(9) != &d {
+		t.Errorf("sibling list broken after removing a middle child")
+	}
+
+	// first child
+	if removed = a.RemoveNthChild(1); removed != &b {
+		t.Errorf("expected b to be removed, got %+v", removed)
+	}
+	if !reflect.DeepEqual(bfsKeys(removed), []interface{}{"b"}) {
+		t.Errorf("removed first child still linked to its siblings: %+v", bfsKeys(removed))
+	}
+
+	// last remaining child
+	if removed = a.RemoveNthChild(9); removed != &d {
+		t.Errorf("expected d to be removed, got %+v", removed)
+	}
+
+	leaf := karytree.NewNode[interface{}]("a")
+	if !karytree.Equals(&a, &leaf) {
+		t.Errorf("expected a to have no children left")
+	}
+}
+
+func TestDetach(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(3, &c)
+
+	b.Detach()
+	if b.Parent() != nil || a.NthChild(0) != nil || a.NthChild(3) != &c {
+		t.Errorf("detach didn't remove b from a")
+	}
+
+	// detaching a root is a no-op
+	a.Detach()
+	if a.NthChild(3) != &c {
+		t.Errorf("detaching a root shouldn't change it")
+	}
+
+	// setting an attached node elsewhere moves it
+	d := karytree.NewNode[interface{}]("d")
+	d.SetNthChild(5, &c)
+	if a.NthChild(3) != nil || d.NthChild(5) != &c || c.Parent() != &d {
+		t.Errorf("expected c to be moved from a to d")
+	}
+}
+
+func TestReplaceSubtree(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+	e := karytree.NewNode[interface{}]("e")
+
+	a.SetNthChild(2, &b)
+	a.SetNthChild(6, &c)
+	d.SetNthChild(0, &e)
+
+	b.ReplaceSubtree(&d)
+
+	if b.Parent() != nil || a.NthChild(2) != &d || d.Parent() != &a {
+		t.Errorf("expected d to replace b at index 2")
+	}
+	if !reflect.DeepEqual(bfsKeys(&a), []interface{}{"a", "d", "c", "e"}) {
+		t.Errorf("unexpected tree after replace: %+v", bfsKeys(&a))
+	}
+	if !reflect.DeepEqual(bfsKeys(&b), []interface{}{"b"}) {
+		t.Errorf("replaced node still linked: %+v", bfsKeys(&b))
+	}
+}
+
+func TestMoveChild(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(3, &c)
+	b.SetNthChild(1, &d)
+
+	if evicted := a.MoveChild(0, 7); evicted != nil {
+		t.Errorf("expected nothing to be evicted, got %+v", evicted)
+	}
+
+	a_ := karytree.NewNode[interface{}]("a")
+	b_ := karytree.NewNode[interface{}]("b")
+	c_ := karytree.NewNode[interface{}]("c")
+	d_ := karytree.NewNode[interface{}]("d")
+
+	a_.SetNthChild(3, &c_)
+	a_.SetNthChild(7, &b_)
+	b_.SetNthChild(1, &d_)
+
+	if !karytree.Equals(&a, &a_) {
+		t.Errorf("moving a child broke the sorted sibling list")
+	}
+
+	if evicted := a.MoveChild(7, 3); evicted != &c {
+		t.Errorf("expected c to be evicted, got %+v", evicted)
+	}
+	if a.NthChild(3) != &b || a.NthChild(7) != nil {
+		t.Errorf("expected b to be moved to index 3")
+	}
+
+	if evicted := a.MoveChild(5, 6); evicted != nil || a.NthChild(6) != nil {
+		t.Errorf("moving a missing child should do nothing")
+	}
+}
+
+// TestRelinkByValueRoot runs the operations that go through parent
+// links on trees from constructTree, whose roots come from NewNode by
+// value.
+func TestRelinkByValueRoot(t *testing.T) {
+	keys := func(node *karytree.Node[interface{}]) []interface{} {
+		keys := []interface{}{}
+		for _, child := range node.Children() {
+			keys = append(keys, child.Key())
+		}
+		return keys
+	}
+
+	// 1, 14 and 27 are the children of the root of constructTree(3)
+	tree, other := constructTree(3), constructTree(3)
+	moved := tree.NthChild(0)
+	other.NthChild(2).SetNthChild(3, moved)
+	if !reflect.DeepEqual(keys(tree), []interface{}{14, 27}) || moved.Parent() != other.NthChild(2) {
+		t.Errorf("expected SetNthChild to move child 0 out of the root, got %+v", keys(tree))
+	}
+	if !reflect.DeepEqual(keys(other.NthChild(2)), []interface{}{28, 32, 36, 1}) {
+		t.Errorf("unexpected children at the destination: %+v", keys(other.NthChild(2)))
+	}
+
+	tree = constructTree(3)
+	tree.NthChild(1).Detach()
+	if !reflect.DeepEqual(keys(tree), []interface{}{1, 27}) {
+		t.Errorf("expected Detach to remove child 1 from the root, got %+v", keys(tree))
+	}
+
+	tree = constructTree(3)
+	if removed := tree.RemoveNthChild(0); removed == nil || removed.Key() != 1 || !reflect.DeepEqual(keys(tree), []interface{}{14, 27}) {
+		t.Errorf("expected RemoveNthChild to remove child 0, got %+v", keys(tree))
+	}
+
+	tree = constructTree(3)
+	leaf := karytree.NewNode[interface{}]("leaf")
+	tree.NthChild(2).ReplaceSubtree(&leaf)
+	if leaf.Parent() != tree || !reflect.DeepEqual(keys(tree), []interface{}{1, 14, "leaf"}) {
+		t.Errorf("expected ReplaceSubtree to replace child 2, got %+v", keys(tree))
+	}
+
+	tree = constructTree(3)
+	tree.MoveChild(0, 4)
+	if tree.NthChild(4).Parent() != tree || !reflect.DeepEqual(keys(tree), []interface{}{14, 27, 1}) {
+		t.Errorf("expected MoveChild to move child 0 to index 4, got %+v", keys(tree))
+	}
+}
+
+func TestKaryNodeIndexOutOfRange(t *testing.T) {
+	a := karytree.NewKaryNode[interface{}]("a", 3)
+	b := karytree.NewKaryNode[interface{}]("b", 3)
+	c := karytree.NewKaryNode[interface{}]("c", 3)
+
+	if a.K() != 3 {
+		t.Errorf("expected k=3, got %d", a.K())
+	}
+
+	if _, err := a.TrySetNthChild(2, &b); err != nil {
+		t.Errorf("expected 2nd child to be in range for k=3, got %v", err)
+	}
+
+	if _, err := a.TrySetNthChild(3, &c); !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for 3rd child of k=3, got %v", err)
+	}
+	if c.Parent() != nil || a.NthChild(3) != nil {
+		t.Errorf("out of range child shouldn't have been attached")
+	}
+
+	if child, err := a.TryNthChild(2); err != nil || child != &b {
+		t.Errorf("expected b at index 2, got %+v, %v", child, err)
+	}
+	if _, err := a.TryNthChild(7); !errors.Is(err, karytree.ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange for 7th child of k=3, got %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected SetNthChild to panic for an out of range index")
+		} else if err, ok := r.(error); !ok || !errors.Is(err, karytree.ErrIndexOutOfRange) {
+			t.Errorf("expected panic with ErrIndexOutOfRange, got %v", r)
+		}
+	}()
+	a.SetNthChild(5, &c)
+}
+
+func TestUnboundedNode(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+
+	if a.K() != 0 {
+		t.Errorf("expected NewNode to be unbounded, got k=%d", a.K())
+	}
+
+	if _, err := a.TrySetNthChild(^uint(0), &b); err != nil {
+		t.Errorf("unbounded nodes should accept any index, got %v", err)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	a := karytree.NewNode[interface{}]("a")
+	b := karytree.NewNode[interface{}]("b")
+	c := karytree.NewNode[interface{}]("c")
+	d := karytree.NewNode[interface{}]("d")
+
+	if !a.IsLeaf() || a.NumChildren() != 0 || a.FirstChild() != nil || a.LastChild() != nil {
+		t.Errorf("expected a fresh node to be a leaf")
+	}
+
+	a.SetNthChild(17, &b)
+	a.SetNthChild(3, &c)
+	a.SetNthChild(9, &d)
+
+	indices := []uint{}
+	children := []*karytree.Node[interface{}]{}
+	for n, child := range a.Children() {
+		indices = append(indices, n)
+		children = append(children, child)
+	}
+
+	if !reflect.DeepEqual(indices, []uint{3, 9, 17}) {
+		t.Errorf("expected child indices [3 9 17], got %+v", indices)
+	}
+	if !reflect.DeepEqual(children, []*karytree.Node[interface{}]{&c, &d, &b}) {
+		t.Errorf("expected children [c d b], got %+v", children)
+	}
+
+	if a.IsLeaf() || a.NumChildren() != 3 {
+		t.Errorf("expected a to have 3 children, got %d", a.NumChildren())
+	}
+	if a.FirstChild() != &c || a.LastChild() != &b {
+		t.Errorf("expected first child c and last child b")
+	}
+
+	if c.NextSibling() != &d || d.NextSibling() != &b || b.NextSibling() != nil {
+		t.Errorf("unexpected next siblings")
+	}
+	if c.PrevSibling() != nil || d.PrevSibling() != &c || b.PrevSibling() != &d {
+		t.Errorf("unexpected previous siblings")
+	}
+	if a.PrevSibling() != nil || a.NextSibling() != nil {
+		t.Errorf("a root has no siblings")
+	}
+
+	if b.ChildIndex() != 17 || c.ChildIndex() != 3 || d.ChildIndex() != 9 {
+		t.Errorf("unexpected child indices %d %d %d", b.ChildIndex(), c.ChildIndex(), d.ChildIndex())
+	}
+
+	ctr := 0
+	for n := range a.Children() {
+		ctr++
+		if n == 9 {
+			break
+		}
+	}
+	if ctr != 2 {
+		t.Errorf("expected to stop after 2 children, got %d", ctr)
+	}
+}
+
+func TestChildrenRemoveWhileRanging(t *testing.T) {
+	tree := constructTree(3)
+	other := karytree.NewNode[interface{}]("other")
+
+	visited := []uint{}
+	for n, child := range tree.Children() {
+		visited = append(visited, n)
+		switch n {
+		case 0:
+			tree.RemoveNthChild(n)
+		case 1:
+			child.Detach()
+		case 2:
+			other.SetNthChild(n, child)
+		}
+	}
+	if !reflect.DeepEqual(visited, []uint{0, 1, 2}) {
+		t.Errorf("expected to visit children [0 1 2], got %+v", visited)
+	}
+	if !tree.IsLeaf() || other.NumChildren() != 1 {
+		t.Errorf("expected every child to be removed, got %v", tree)
+	}
+
+	dense := karytree.NewDenseNode(0, 4)
+	leaves := []karytree.Node[int]{karytree.NewNode(1), karytree.NewNode(2), karytree.NewNode(3)}
+	for i := range leaves {
+		dense.SetNthChild(uint(i), &leaves[i])
+	}
+	count := 0
+	for n := range dense.Children() {
+		dense.RemoveNthChild(n)
+		count++
+	}
+	if count != 3 || !dense.IsLeaf() {
+		t.Errorf("expected to remove 3 children from a dense node, removed %d", count)
+	}
 }
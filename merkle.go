@@ -0,0 +1,184 @@
+package karytree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+)
+
+// A Digest is the Merkle hash of a subtree.
+type Digest [sha256.Size]byte
+
+func (d Digest) String() string {
+	return hex.EncodeToString(d[:])
+}
+
+// A KeyHasher writes the bytes that identify key to h. Two keys should
+// only be written the same way if they are equal.
+type KeyHasher[T comparable] func(h hash.Hash, key T)
+
+// cachedDigest is the digest of a node, and the Hasher that computed it,
+// or nil if there is none.
+type cachedDigest[T comparable] struct {
+	hasher *Hasher[T]
+	sum    Digest
+}
+
+// A Hasher computes Merkle digests of subtrees, and caches them in the
+// nodes.
+//
+// The digest of a node is the SHA-256 of the digest of its key, followed
+// by the child index and digest of each of its children in ascending
+// order. It covers the same things as Equals, except for the child index
+// of the root itself, which Equals compares: a subtree hashes the same
+// wherever it is. Neither covers the arity k.
+//
+// A cached digest is invalidated along with those of its ancestors when
+// a node is changed by SetKey, SetNthChild, RemoveNthChild or any of the
+// other methods of Node, so rehashing a tree after a change only costs
+// as much as the path from the change to the root. Since hashing writes
+// the cache, it must not run concurrently with anything else on the
+// tree.
+type Hasher[T comparable] struct {
+	keyHasher KeyHasher[T]
+	keyHash   hash.Hash
+}
+
+// NewHasher returns a Hasher that uses keyHasher for keys. Each Hasher
+// has its own cache: a node only remembers the digest of the last Hasher
+// used on it.
+func NewHasher[T comparable](keyHasher KeyHasher[T]) *Hasher[T] {
+	return &Hasher[T]{keyHasher: keyHasher, keyHash: sha256.New()}
+}
+
+// Hash computes the Merkle digest of the tree rooted at root, without
+// caching it. It is the same digest as computed by a Hasher.
+func Hash[T comparable](root *Node[T], keyHasher KeyHasher[T]) Digest {
+	h := Hasher[T]{keyHasher: keyHasher, keyHash: sha256.New()}
+	return h.hash(root, false)
+}
+
+// Hash computes the Merkle digest of the tree rooted at root, reusing
+// and filling the cached digests of its subtrees.
+func (h *Hasher[T]) Hash(root *Node[T]) Digest {
+	return h.hash(root, true)
+}
+
+func (h *Hasher[T]) hash(node *Node[T], cache bool) Digest {
+	if cache {
+		if sum, ok := node.digest(h); ok {
+			return sum
+		}
+	}
+
+	h.keyHash.Reset()
+	h.keyHasher(h.keyHash, node.key)
+	buf := h.keyHash.Sum(make([]byte, 0, sha256.Size))
+
+	for child := node.firstChild; child != nil; child = child.nextSibling {
+		sum := h.hash(child, cache)
+		buf = binary.AppendUvarint(buf, uint64(child.n))
+		buf = append(buf, sum[:]...)
+	}
+
+	sum := Digest(sha256.Sum256(buf))
+	if cache {
+		node.extension().digest = cachedDigest[T]{hasher: h, sum: sum}
+	}
+	return sum
+}
+
+// invalidate drops the cached digests of k and its ancestors. The
+// ancestors of a node without a cached digest can't have one either,
+// since hashing a node caches its whole subtree, so it stops there.
+func (k *Node[T]) invalidate() {
+	for curr := k; curr != nil && curr.ext != nil && curr.ext.digest.hasher != nil; curr = curr.parent {
+		curr.ext.digest = cachedDigest[T]{}
+		curr.trimExtension()
+	}
+}
+
+// digest gets the digest of k cached by h, if any.
+func (k *Node[T]) digest(h *Hasher[T]) (Digest, bool) {
+	if k.ext == nil || k.ext.digest.hasher != h {
+		return Digest{}, false
+	}
+	return k.ext.digest.sum, true
+}
+
+// Equal compares the trees rooted at a and b like Equals, but returns
+// false as soon as two nodes have different digests cached by h, instead
+// of comparing their subtrees. The cache can be trusted as long as the
+// KeyHasher always writes equal keys the same way, and keys aren't
+// changed in place behind SetKey, e.g. through a pointer, since a node
+// hashed before such a change keeps its old digest.
+func (h *Hasher[T]) Equal(a, b *Node[T]) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil || a.n != b.n || a.key != b.key {
+		return false
+	}
+	if sumA, ok := a.digest(h); ok {
+		if sumB, ok := b.digest(h); ok && sumA != sumB {
+			return false
+		}
+	}
+
+	ca, cb := a.firstChild, b.firstChild
+	for ; ca != nil && cb != nil; ca, cb = ca.nextSibling, cb.nextSibling {
+		if !h.Equal(ca, cb) {
+			return false
+		}
+	}
+	return ca == nil && cb == nil
+}
+
+// Changed returns the paths of the nodes that changed between the trees
+// rooted at a and b, e.g. two snapshots of the same tree. A node changed
+// if it exists in both trees, but its key or the child indices of its
+// children differ; nodes that only exist in one tree are covered by
+// their parent. Subtrees with equal digests are skipped, so with cached
+// digests this costs as much as the changes, not the trees.
+//
+// Unlike Equals, Changed trusts the digests, so it relies on the
+// KeyHasher writing different keys differently.
+func (h *Hasher[T]) Changed(a, b *Node[T]) [][]uint {
+	paths := [][]uint{}
+	h.changed(a, b, []uint{}, &paths)
+	return paths
+}
+
+func (h *Hasher[T]) changed(a, b *Node[T], path []uint, paths *[][]uint) {
+	if h.Hash(a) == h.Hash(b) {
+		return
+	}
+
+	local := a.key != b.key
+	ca, cb := a.firstChild, b.firstChild
+	for ca != nil || cb != nil {
+		if ca == nil || cb == nil || ca.n != cb.n {
+			local = true
+			break
+		}
+		ca, cb = ca.nextSibling, cb.nextSibling
+	}
+	if local {
+		*paths = append(*paths, path)
+	}
+
+	ca, cb = a.firstChild, b.firstChild
+	for ca != nil && cb != nil {
+		switch {
+		case ca.n < cb.n:
+			ca = ca.nextSibling
+		case cb.n < ca.n:
+			cb = cb.nextSibling
+		default:
+			childPath := append(append(make([]uint, 0, len(path)+1), path...), ca.n)
+			h.changed(ca, cb, childPath, paths)
+			ca, cb = ca.nextSibling, cb.nextSibling
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package karytree_test
+
+import (
+	"hash"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func hashString(h hash.Hash, key string) {
+	io.WriteString(h, key)
+}
+
+func TestHashEqualTrees(t *testing.T) {
+	a := mustParse(t, "(a [1](b [9]d) [4]c)")
+	b := mustParse(t, "(a [1](b [9]d) [4]c)")
+
+	if karytree.Hash(a, hashString) != karytree.Hash(b, hashString) {
+		t.Errorf("expected equal trees to have equal hashes")
+	}
+
+	for _, s := range []string{
+		"(x [1](b [9]d) [4]c)",
+		"(a [1](b [8]d) [4]c)",
+		"(a [1](b [9]d) [4]c e)",
+		"(a [1](b [9]d))",
+		"(a [1]b [4](c [9]d))",
+	} {
+		other := mustParse(t, s)
+		if karytree.Hash(a, hashString) == karytree.Hash(other, hashString) {
+			t.Errorf("expected %v and %v to have different hashes", a, other)
+		}
+	}
+}
+
+func TestHasherCacheInvalidation(t *testing.T) {
+	h := karytree.NewHasher(hashString)
+	tree := mustParse(t, "(a (b e f) c (d g))")
+	h.Hash(tree)
+
+	check := func(what string) {
+		t.Helper()
+		if h.Hash(tree) != karytree.Hash(tree, hashString) {
+			t.Errorf("stale cached hash after %s", what)
+		}
+	}
+
+	tree.NthChild(0).NthChild(1).SetKey("x")
+	check("SetKey")
+
+	leaf := karytree.NewNode("y")
+	tree.NthChild(2).NthChild(0).SetNthChild(3, &leaf)
+	check("SetNthChild")
+
+	tree.NthChild(2).RemoveNthChild(0)
+	check("RemoveNthChild")
+
+	tree.MoveChild(1, 7)
+	check("MoveChild")
+
+	detached := tree.NthChild(0)
+	detached.Detach()
+	check("Detach")
+	if h.Hash(detached) != karytree.Hash(detached, hashString) {
+		t.Errorf("stale cached hash on a detached subtree")
+	}
+
+	other := mustParse(t, "(z w)")
+	h.Hash(other)
+	tree.SetNthChild(7, other)
+	check("eviction")
+}
+
+func TestHasherEqual(t *testing.T) {
+	h := karytree.NewHasher(hashString)
+	a := mustParse(t, "(a (b e f) c (d g))")
+	b := mustParse(t, "(a (b e f) c (d g))")
+	h.Hash(a)
+	h.Hash(b)
+
+	if !h.Equal(a, b) {
+		t.Errorf("expected equal trees with cached hashes to be equal")
+	}
+
+	b.NthChild(2).NthChild(0).SetKey("x")
+	h.Hash(b)
+	if h.Equal(a, b) {
+		t.Errorf("expected different trees with cached hashes to differ")
+	}
+
+	b.NthChild(2).NthChild(0).SetKey("g")
+	if !h.Equal(a, b) {
+		t.Errorf("expected trees to be equal again after reverting a change")
+	}
+}
+
+func TestEqualsIgnoresCachedHashes(t *testing.T) {
+	// a pointer key changed in place leaves a stale digest behind
+	key := "a"
+	h := karytree.NewHasher(func(h hash.Hash, key *string) {
+		io.WriteString(h, *key)
+	})
+	a := karytree.NewNode(&key)
+	h.Hash(&a)
+	key = "b"
+	b := karytree.NewNode(&key)
+	h.Hash(&b)
+
+	if !karytree.Equals(&a, &b) {
+		t.Errorf("expected nodes with the same key to be equal despite their cached hashes")
+	}
+	if h.Equal(&a, &b) {
+		t.Errorf("expected Hasher.Equal to trust the cached hashes")
+	}
+}
+
+func TestHasherChanged(t *testing.T) {
+	h := karytree.NewHasher(hashString)
+	before := mustParse(t, "(a (b e f) c (d g [5]h))")
+	after := mustParse(t, "(a (b e x) c (d g [6]h))")
+
+	expected := [][]uint{{0, 1}, {2}}
+	if changed := h.Changed(before, after); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected changes at %v, got %v", expected, changed)
+	}
+
+	if changed := h.Changed(before, before); len(changed) != 0 {
+		t.Errorf("expected no changes, got %v", changed)
+	}
+
+	after.SetKey("z")
+	expected = [][]uint{{}, {0, 1}, {2}}
+	if changed := h.Changed(before, after); !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected changes at %v, got %v", expected, changed)
+	}
+}
@@ -0,0 +1,388 @@
+package karytree
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A SyntaxError is returned by ParseSExpr and ParseNewick when their
+// input isn't valid.
+type SyntaxError struct {
+	// Offset is the byte offset in the input where the error occurred.
+	Offset int
+	msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("karytree: syntax error at offset %d: %s", e.Offset, e.msg)
+}
+
+// parser holds the state shared by the S-expression and Newick parsers.
+type parser struct {
+	s   string
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{Offset: p.pos, msg: fmt.Sprintf(format, args...)}
+}
+
+// peek returns the next byte, or 0 at the end of the input.
+func (p *parser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n\r", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// bare scans a token that runs until the first byte in stop.
+func (p *parser) bare(stop string) string {
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte(stop, p.s[p.pos]) < 0 {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// index scans an optional child index annotation [n].
+func (p *parser) index() (uint, bool, error) {
+	p.skipSpace()
+	if p.peek() != '[' {
+		return 0, false, nil
+	}
+	p.pos++
+	p.skipSpace()
+	start := p.pos
+	digits := p.bare(" \t\n\r]")
+	n, err := strconv.ParseUint(digits, 10, 0)
+	if err != nil {
+		p.pos = start
+		return 0, false, p.errorf("bad child index %q", digits)
+	}
+	p.skipSpace()
+	if p.peek() != ']' {
+		return 0, false, p.errorf("expected ']' after child index")
+	}
+	p.pos++
+	return uint(n), true, nil
+}
+
+// parseKeyAt converts a token that started at offset start to a key.
+func parseKeyAt[T comparable](p *parser, start int, s string, parseKey func(string) (T, error)) (T, error) {
+	key, err := parseKey(s)
+	if err != nil {
+		err = fmt.Errorf("karytree: bad key %q at offset %d: %w", s, start, err)
+	}
+	return key, err
+}
+
+// childIndex picks the index of the next child: the explicit index if
+// there is one, or the one after prev, the index of the previous child
+// unless first is true. No child can follow one at index math.MaxUint.
+func (p *parser) childIndex(prev uint, first bool) (uint, error) {
+	start := p.pos
+	n, ok, err := p.index()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case first && !ok:
+		return 0, nil
+	case first:
+		return n, nil
+	case !ok && prev == math.MaxUint:
+		p.pos = start
+		return 0, p.errorf("no child index after %d", prev)
+	case !ok:
+		return prev + 1, nil
+	case n <= prev:
+		p.pos = start
+		return 0, p.errorf("child index %d isn't above the previous one", n)
+	}
+	return n, nil
+}
+
+// ParseSExpr builds a tree from an S-expression, such as
+// (a (b e f) c (d g)), converting each key with parseKey. A node with
+// children is a parenthesized list of its key followed by its children,
+// and a leaf is its key, optionally in parentheses. Keys are either bare
+// words, or Go-quoted strings if they contain spaces, parentheses,
+// brackets or quotes.
+//
+// Children are numbered from 0 in order. A child can be prefixed by an
+// explicit index [n] for sparse positions, and the following children
+// are numbered from n+1, so (a [1]b [4]c d) has children at 1, 4 and 5.
+// Indices must be ascending, so no child can follow one at index
+// math.MaxUint. This is the compact form printed by %v.
+//
+// The nodes are created with NewNode, so they are unbounded.
+func ParseSExpr[T comparable](s string, parseKey func(string) (T, error)) (*Node[T], error) {
+	p := &parser{s: s}
+	root, err := parseSExpr(p, parseKey)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after the tree", p.s[p.pos])
+	}
+	return root, nil
+}
+
+func parseSExpr[T comparable](p *parser, parseKey func(string) (T, error)) (*Node[T], error) {
+	p.skipSpace()
+	if p.peek() != '(' {
+		return parseSExprKey(p, parseKey)
+	}
+	p.pos++
+
+	node, err := parseSExprKey(p, parseKey)
+	if err != nil {
+		return nil, err
+	}
+
+	prev, first := uint(0), true
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case ')':
+			p.pos++
+			return node, nil
+		case 0:
+			return nil, p.errorf("missing ')'")
+		}
+
+		n, err := p.childIndex(prev, first)
+		if err != nil {
+			return nil, err
+		}
+		child, err := parseSExpr(p, parseKey)
+		if err != nil {
+			return nil, err
+		}
+		node.setNthChild(n, child)
+		prev, first = n, false
+	}
+}
+
+func parseSExprKey[T comparable](p *parser, parseKey func(string) (T, error)) (*Node[T], error) {
+	p.skipSpace()
+	start := p.pos
+
+	var s string
+	if p.peek() == '"' {
+		p.pos++
+		for p.pos < len(p.s) && p.s[p.pos] != '"' {
+			if p.s[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.s) {
+			p.pos = start
+			return nil, p.errorf("unterminated quoted key")
+		}
+		p.pos++
+
+		quoted := p.s[start:p.pos]
+		var err error
+		s, err = strconv.Unquote(quoted)
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("bad quoted key %s", quoted)
+		}
+	} else {
+		s = p.bare(" \t\n\r()[]\"")
+		if s == "" {
+			if p.pos >= len(p.s) {
+				return nil, p.errorf("unexpected end of input")
+			}
+			return nil, p.errorf("unexpected %q, expected a key", p.s[p.pos])
+		}
+	}
+
+	key, err := parseKeyAt(p, start, s, parseKey)
+	if err != nil {
+		return nil, err
+	}
+	node := NewNode(key)
+	return &node, nil
+}
+
+// WriteSExpr writes the tree rooted at root to w as an S-expression that
+// ParseSExpr can read back, with keys printed by fmt.Sprint. It is the
+// same as the compact form printed by %v.
+func WriteSExpr[T comparable](w io.Writer, root *Node[T]) error {
+	if root == nil {
+		return errors.New("karytree: can't write a nil tree")
+	}
+	bw := bufio.NewWriter(w)
+	root.writeCompact(bw, -1)
+	return bw.Flush()
+}
+
+// newickStop are the bytes that end an unquoted Newick label.
+const newickStop = " \t\n\r()[]':;,"
+
+// ParseNewick builds a tree from the Newick format used in
+// phylogenetics, such as ((e,f)b,c,(g)d)a; converting each label with
+// parseKey. The children of a node are listed in parentheses before its
+// label. Labels that contain spaces or punctuation are single-quoted,
+// with a doubled quote standing for a quote, and missing labels are
+// passed to parseKey as "". Branch lengths (:1.5) are accepted, but
+// discarded.
+//
+// Children are numbered from 0 in order. Like in ParseSExpr, a child
+// can be prefixed by an explicit index [n] for sparse positions, which
+// other Newick readers will skip as a comment.
+//
+// The nodes are created with NewNode, so they are unbounded.
+func ParseNewick[T comparable](s string, parseKey func(string) (T, error)) (*Node[T], error) {
+	p := &parser{s: s}
+	root, err := parseNewick(p, parseKey)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ';' {
+		return nil, p.errorf("expected ';' at the end of the tree")
+	}
+	p.pos++
+	p.skipSpace()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q after the tree", p.s[p.pos])
+	}
+	return root, nil
+}
+
+func parseNewick[T comparable](p *parser, parseKey func(string) (T, error)) (*Node[T], error) {
+	var children []*Node[T]
+	var indices []uint
+
+	p.skipSpace()
+	if p.peek() == '(' {
+		p.pos++
+		prev, first := uint(0), true
+		for {
+			n, err := p.childIndex(prev, first)
+			if err != nil {
+				return nil, err
+			}
+			child, err := parseNewick(p, parseKey)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
+			indices = append(indices, n)
+			prev, first = n, false
+
+			p.skipSpace()
+			if p.peek() == ',' {
+				p.pos++
+				continue
+			}
+			if p.peek() != ')' {
+				return nil, p.errorf("expected ',' or ')'")
+			}
+			p.pos++
+			break
+		}
+	}
+
+	p.skipSpace()
+	start := p.pos
+	var s string
+	if p.peek() == '\'' {
+		var sb strings.Builder
+		p.pos++
+		for {
+			if p.pos >= len(p.s) {
+				p.pos = start
+				return nil, p.errorf("unterminated quoted label")
+			}
+			c := p.s[p.pos]
+			p.pos++
+			if c == '\'' {
+				if p.peek() != '\'' {
+					break
+				}
+				p.pos++
+			}
+			sb.WriteByte(c)
+		}
+		s = sb.String()
+	} else {
+		s = p.bare(newickStop)
+	}
+
+	key, err := parseKeyAt(p, start, s, parseKey)
+	if err != nil {
+		return nil, err
+	}
+	node := NewNode(key)
+	for i, child := range children {
+		node.setNthChild(indices[i], child)
+	}
+
+	p.skipSpace()
+	if p.peek() == ':' {
+		p.pos++
+		p.skipSpace()
+		lengthStart := p.pos
+		length := p.bare(newickStop)
+		if _, err := strconv.ParseFloat(length, 64); err != nil {
+			p.pos = lengthStart
+			return nil, p.errorf("bad branch length %q", length)
+		}
+	}
+
+	return &node, nil
+}
+
+// WriteNewick writes the tree rooted at root to w in the Newick format
+// that ParseNewick can read back, with labels printed by fmt.Sprint.
+// Sparse children are prefixed by their index [n], like in %v.
+func WriteNewick[T comparable](w io.Writer, root *Node[T]) error {
+	if root == nil {
+		return errors.New("karytree: can't write a nil tree")
+	}
+	bw := bufio.NewWriter(w)
+	root.writeNewick(bw)
+	bw.WriteString(";")
+	return bw.Flush()
+}
+
+func (k *Node[T]) writeNewick(w *bufio.Writer) {
+	if k.firstChild != nil {
+		w.WriteByte('(')
+		var prev *Node[T]
+		for child := k.firstChild; child != nil; child = child.nextSibling {
+			if prev != nil {
+				w.WriteByte(',')
+			}
+			if sparse(prev, child) {
+				fmt.Fprintf(w, "[%d]", child.n)
+			}
+			child.writeNewick(w)
+			prev = child
+		}
+		w.WriteByte(')')
+	}
+
+	label := fmt.Sprint(k.key)
+	if strings.ContainsAny(label, newickStop) {
+		label = "'" + strings.ReplaceAll(label, "'", "''") + "'"
+	}
+	w.WriteString(label)
+}
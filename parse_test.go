@@ -0,0 +1,190 @@
+package karytree_test
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func TestParseSExpr(t *testing.T) {
+	tree, err := karytree.ParseSExpr("(a (b e f) c (d g))", parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	e := karytree.NewNode("e")
+	f := karytree.NewNode("f")
+	g := karytree.NewNode("g")
+	a.SetNthChild(0, &b)
+	a.SetNthChild(1, &c)
+	a.SetNthChild(2, &d)
+	b.SetNthChild(0, &e)
+	b.SetNthChild(1, &f)
+	d.SetNthChild(0, &g)
+
+	if !karytree.Equals(&a, tree) {
+		t.Errorf("expected %v, got %v", &a, tree)
+	}
+	if tree.NthChild(2).NthChild(0).Parent() != tree.NthChild(2) {
+		t.Errorf("parent links weren't set")
+	}
+}
+
+func TestParseSExprSparse(t *testing.T) {
+	tree, err := karytree.ParseSExpr(`( a [1] ( b [9]d ) [4]c "x y" )`, parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	for path, expected := range map[string]string{
+		"1": "b", "1.9": "d", "4": "c", "5": "x y",
+	} {
+		node := tree
+		for _, s := range strings.Split(path, ".") {
+			n, _ := strconv.Atoi(s)
+			node = node.NthChild(uint(n))
+		}
+		if node == nil || node.Key() != expected {
+			t.Errorf("expected %s at %s, got %v", expected, path, node)
+		}
+	}
+	if tree.NumChildren() != 3 {
+		t.Errorf("expected 3 children, got %d", tree.NumChildren())
+	}
+}
+
+func TestParseSExprMaxIndex(t *testing.T) {
+	tree, err := karytree.ParseSExpr("(a b [18446744073709551615]c)", parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if c := tree.NthChild(math.MaxUint); c == nil || c.Key() != "c" || tree.NumChildren() != 2 {
+		t.Errorf("expected c at index %d, got %v", uint(math.MaxUint), tree)
+	}
+}
+
+func TestParseSExprMatchesConstructTree(t *testing.T) {
+	expected := constructTree(2)
+
+	tree, err := karytree.ParseSExpr("(0 (1 (2 3 4) (5 6 7)) (8 (9 10 11) (12 13 14)))", func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	if !karytree.Equals(expected, tree) {
+		t.Errorf("expected %v, got %v", expected, tree)
+	}
+}
+
+func TestSExprRoundTrip(t *testing.T) {
+	tree := constructTreeSparse(6)
+
+	var sb strings.Builder
+	if err := karytree.WriteSExpr(&sb, tree); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	parsed, err := karytree.ParseSExpr(sb.String(), func(s string) (interface{}, error) {
+		return strconv.Atoi(s)
+	})
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	if !karytree.Equals(tree, parsed) {
+		t.Errorf("expected %v, got %v", tree, parsed)
+	}
+}
+
+func TestParseSExprErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"(a b",
+		"(a [2]b [1]c)",
+		"(a [x]b)",
+		"(a [1 b)",
+		"(a b) c",
+		`(a "b)`,
+		"()",
+		"(a [18446744073709551615]b c)",
+		"(a [18446744073709551615]b [18446744073709551615]c)",
+	} {
+		var syntaxErr *karytree.SyntaxError
+		if _, err := karytree.ParseSExpr(s, parseString); !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a SyntaxError, got %v", s, err)
+		}
+	}
+
+	_, err := karytree.ParseSExpr("(1 2 x)", strconv.Atoi)
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected the key parser's error, got %v", err)
+	}
+}
+
+func TestParseNewick(t *testing.T) {
+	tree, err := karytree.ParseNewick("((e:1,f:2.5)b, c ,(g)d)a;", parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	expected, _ := karytree.ParseSExpr("(a (b e f) c (d g))", parseString)
+	if !karytree.Equals(expected, tree) {
+		t.Errorf("expected %v, got %v", expected, tree)
+	}
+}
+
+func TestNewickRoundTrip(t *testing.T) {
+	tree, err := karytree.ParseSExpr(`(a [1](b [9]d) [4]"it's c" "")`, parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	var sb strings.Builder
+	if err := karytree.WriteNewick(&sb, tree); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	expected := "([1]([9]d)b,[4]'it''s c',)a;"
+	if sb.String() != expected {
+		t.Errorf("expected %s, got %s", expected, sb.String())
+	}
+
+	parsed, err := karytree.ParseNewick(sb.String(), parseString)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if !karytree.Equals(tree, parsed) {
+		t.Errorf("expected %v, got %v", tree, parsed)
+	}
+}
+
+func TestParseNewickErrors(t *testing.T) {
+	for _, s := range []string{
+		"(a,b)c",
+		"(a,b c;",
+		"(a:x)b;",
+		"('a,b)c;",
+		"([3]a,[1]b)c;",
+		"a;b",
+		"([18446744073709551615]a,b)c;",
+	} {
+		var syntaxErr *karytree.SyntaxError
+		if _, err := karytree.ParseNewick(s, parseString); !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a SyntaxError, got %v", s, err)
+		}
+	}
+}
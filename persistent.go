@@ -0,0 +1,274 @@
+package karytree
+
+import (
+	"fmt"
+	"iter"
+)
+
+// A Persistent is an immutable tree node, with the same child-sibling
+// layout as Node but without parent links, so that subtrees can be
+// shared between versions of a tree.
+//
+// The With* methods and Update never modify a Persistent. They return a
+// new version of the tree that copies the path from the root to the
+// change, and the siblings before it in each sibling list, and shares
+// every other subtree with the old version. Old versions stay valid, so
+// a Persistent can be read from any number of goroutines while another
+// one publishes new versions, e.g. through an atomic.Pointer.
+type Persistent[T comparable] struct {
+	key         T
+	n           uint
+	k           uint
+	firstChild  *Persistent[T]
+	nextSibling *Persistent[T]
+}
+
+// NewPersistent creates a new immutable node with data key. Its arity
+// is unbounded.
+func NewPersistent[T comparable](key T) *Persistent[T] {
+	return &Persistent[T]{key: key}
+}
+
+// NewKaryPersistent creates a new immutable node with data key which
+// accepts at most k children, like NewKaryNode.
+func NewKaryPersistent[T comparable](key T, k uint) *Persistent[T] {
+	return &Persistent[T]{key: key, k: k}
+}
+
+// Freeze makes an immutable copy of the tree rooted at root.
+func Freeze[T comparable](root *Node[T]) *Persistent[T] {
+	if root == nil {
+		return nil
+	}
+
+	p := &Persistent[T]{key: root.key, n: root.n, k: root.k}
+	var last *Persistent[T]
+	for child := root.firstChild; child != nil; child = child.nextSibling {
+		c := Freeze(child)
+		if last == nil {
+			p.firstChild = c
+		} else {
+			last.nextSibling = c
+		}
+		last = c
+	}
+	return p
+}
+
+// Thaw makes a mutable copy of the tree rooted at p.
+func (p *Persistent[T]) Thaw() *Node[T] {
+	if p == nil {
+		return nil
+	}
+
+	node := NewKaryNode(p.key, p.k)
+	node.n = p.n
+	var last *Node[T]
+	for child := p.firstChild; child != nil; child = child.nextSibling {
+		c := child.Thaw()
+		c.parent = &node
+		if last == nil {
+			node.firstChild = c
+		} else {
+			last.nextSibling = c
+		}
+		last = c
+	}
+	return &node
+}
+
+// Key gets the data stored in a node.
+func (p *Persistent[T]) Key() T {
+	return p.key
+}
+
+// K gets the arity of a node, or 0 if it is unbounded.
+func (p *Persistent[T]) K() uint {
+	return p.k
+}
+
+// ChildIndex gets the index n at which p is a child of its parent.
+func (p *Persistent[T]) ChildIndex() uint {
+	return p.n
+}
+
+// NthChild gets the Nth child.
+func (p *Persistent[T]) NthChild(n uint) *Persistent[T] {
+	for curr := p.firstChild; curr != nil && curr.n <= n; curr = curr.nextSibling {
+		if curr.n == n {
+			return curr
+		}
+	}
+	return nil
+}
+
+// Children returns an iterator over the children of p in ascending
+// order of their child index, yielding each index with its child.
+func (p *Persistent[T]) Children() iter.Seq2[uint, *Persistent[T]] {
+	return func(yield func(uint, *Persistent[T]) bool) {
+		for curr := p.firstChild; curr != nil; curr = curr.nextSibling {
+			if !yield(curr.n, curr) {
+				return
+			}
+		}
+	}
+}
+
+// NumChildren counts the children of p.
+func (p *Persistent[T]) NumChildren() int {
+	count := 0
+	for curr := p.firstChild; curr != nil; curr = curr.nextSibling {
+		count++
+	}
+	return count
+}
+
+// IsLeaf reports whether p has no children.
+func (p *Persistent[T]) IsLeaf() bool {
+	return p.firstChild == nil
+}
+
+// WithKey returns a copy of p with data key, sharing its children.
+func (p *Persistent[T]) WithKey(key T) *Persistent[T] {
+	ret := *p
+	ret.key = key
+	return &ret
+}
+
+// WithNthChild returns a copy of p whose Nth child is child, replacing
+// any existing Nth child. child itself is reused at index n: its
+// subtree is shared, not copied.
+//
+// Like SetNthChild, WithNthChild panics with ErrIndexOutOfRange if p is
+// bounded and n is out of range.
+func (p *Persistent[T]) WithNthChild(n uint, child *Persistent[T]) *Persistent[T] {
+	if p.k != 0 && n >= p.k {
+		panic(fmt.Errorf("%w: %d >= k=%d", ErrIndexOutOfRange, n, p.k))
+	}
+
+	c := *child
+	c.n = n
+	ret := *p
+	ret.firstChild = withChild(p.firstChild, n, &c)
+	return &ret
+}
+
+// WithoutChild returns a copy of p without its Nth child. If there is
+// no Nth child, p itself is returned.
+func (p *Persistent[T]) WithoutChild(n uint) *Persistent[T] {
+	if p.NthChild(n) == nil {
+		return p
+	}
+
+	ret := *p
+	ret.firstChild = withChild(p.firstChild, n, nil)
+	return &ret
+}
+
+// withChild returns the sibling list starting at first, with the child
+// at index n replaced by c, or removed if c is nil. Only the siblings
+// before index n are copied; the rest of the list is shared.
+func withChild[T comparable](first *Persistent[T], n uint, c *Persistent[T]) *Persistent[T] {
+	if first == nil || first.n > n {
+		if c == nil {
+			return first
+		}
+		c.nextSibling = first
+		return c
+	}
+
+	if first.n == n {
+		if c == nil {
+			return first.nextSibling
+		}
+		c.nextSibling = first.nextSibling
+		return c
+	}
+
+	ret := *first
+	ret.nextSibling = withChild(first.nextSibling, n, c)
+	return &ret
+}
+
+// Update returns a new version of the tree rooted at p, where the node
+// at path (see Node.PathFromRoot) is replaced by f(node). f is called
+// with nil if the last index of path is free, and the node is removed if
+// f returns nil. If any other node along path is missing, f isn't called
+// and p is returned. If path is empty, Update returns f(p).
+func (p *Persistent[T]) Update(path []uint, f func(*Persistent[T]) *Persistent[T]) *Persistent[T] {
+	if len(path) == 0 {
+		return f(p)
+	}
+
+	child := p.NthChild(path[0])
+	var updated *Persistent[T]
+	if len(path) == 1 {
+		updated = f(child)
+	} else if child != nil {
+		updated = child.Update(path[1:], f)
+	} else {
+		return p
+	}
+
+	switch {
+	case updated == child:
+		return p
+	case updated == nil:
+		return p.WithoutChild(path[0])
+	default:
+		return p.WithNthChild(path[0], updated)
+	}
+}
+
+// All returns an iterator over the tree rooted at p in BFS order.
+func (p *Persistent[T]) All() iter.Seq[*Persistent[T]] {
+	return func(yield func(*Persistent[T]) bool) {
+		if p == nil {
+			return
+		}
+
+		queue := []*Persistent[T]{p}
+		var curr *Persistent[T]
+
+		for len(queue) > 0 {
+			curr, queue = queue[0], queue[1:]
+
+			if !yield(curr) {
+				return
+			}
+
+			for next := curr.firstChild; next != nil; next = next.nextSibling {
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// Equals does a deep comparison of two immutable trees, like Equals.
+// Subtrees that are shared between them are equal without being
+// walked, so comparing two versions of a tree only costs as much as the
+// paths that differ.
+func (p *Persistent[T]) Equals(other *Persistent[T]) bool {
+	if p == other {
+		return true
+	}
+	if p == nil || other == nil {
+		return false
+	}
+	if p.n != other.n || p.key != other.key {
+		return false
+	}
+
+	a, b := p.firstChild, other.firstChild
+	for a != nil && b != nil {
+		if a == b {
+			// the rest of the sibling list is shared too
+			return true
+		}
+		if !a.Equals(b) {
+			return false
+		}
+		a, b = a.nextSibling, b.nextSibling
+	}
+	return a == nil && b == nil
+}
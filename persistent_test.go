@@ -0,0 +1,135 @@
+package karytree_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestPersistentFreezeThaw(t *testing.T) {
+	tree := constructTreeSparse(6)
+
+	frozen := karytree.Freeze(tree)
+	thawed := frozen.Thaw()
+	if !karytree.Equals(tree, thawed) {
+		t.Errorf("expected Thaw(Freeze(tree)) to equal tree")
+	}
+
+	count := 0
+	for node := range frozen.All() {
+		if node.NumChildren() == 0 != node.IsLeaf() {
+			t.Errorf("IsLeaf doesn't match NumChildren")
+		}
+		count++
+	}
+	if count != len(zipBFS(tree, tree)) {
+		t.Errorf("expected All to visit %d nodes, got %d", len(zipBFS(tree, tree)), count)
+	}
+}
+
+func TestPersistentPathCopying(t *testing.T) {
+	v1 := karytree.Freeze(mustParse(t, "(a (b e f) c [5](d g))"))
+
+	v2 := v1.Update([]uint{0, 1}, func(node *karytree.Persistent[string]) *karytree.Persistent[string] {
+		return node.WithKey("x")
+	})
+	v3 := v2.Update([]uint{5, 3}, func(node *karytree.Persistent[string]) *karytree.Persistent[string] {
+		return karytree.NewPersistent("y")
+	})
+	v4 := v3.WithoutChild(1)
+
+	for _, tc := range []struct {
+		version  *karytree.Persistent[string]
+		expected string
+	}{
+		{v1, "(a (b e f) c [5](d g))"},
+		{v2, "(a (b e x) c [5](d g))"},
+		{v3, "(a (b e x) c [5](d g [3]y))"},
+		{v4, "(a (b e x) [5](d g [3]y))"},
+	} {
+		if !karytree.Equals(tc.version.Thaw(), mustParse(t, tc.expected)) {
+			t.Errorf("expected %s, got %v", tc.expected, tc.version.Thaw())
+		}
+	}
+
+	if v2.NthChild(5) != v1.NthChild(5) || v2.NthChild(1) != v1.NthChild(1) {
+		t.Errorf("expected the siblings after the change to be shared")
+	}
+	if v2.NthChild(0) == v1.NthChild(0) {
+		t.Errorf("expected the path to the change to be copied")
+	}
+	if v3.NthChild(0) == v2.NthChild(0) || v3.NthChild(0).NthChild(0) != v2.NthChild(0).NthChild(0) {
+		t.Errorf("expected the siblings before a change to be copied, sharing their children")
+	}
+
+	if !v2.Equals(v2.WithKey("a")) || v1.Equals(v2) {
+		t.Errorf("Equals doesn't compare versions correctly")
+	}
+	if v1.Update([]uint{7, 0}, nil) != v1 {
+		t.Errorf("expected Update along a missing path to return the same version")
+	}
+	if v1.WithoutChild(7) != v1 {
+		t.Errorf("expected WithoutChild of a missing child to return the same version")
+	}
+}
+
+func TestPersistentBounded(t *testing.T) {
+	root := karytree.NewKaryPersistent("a", 2)
+	root = root.WithNthChild(1, karytree.NewPersistent("b"))
+	if root.NthChild(1).Key() != "b" || root.NthChild(1).ChildIndex() != 1 {
+		t.Errorf("expected b at index 1")
+	}
+
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, karytree.ErrIndexOutOfRange) {
+			t.Errorf("expected a panic with ErrIndexOutOfRange, got %v", err)
+		}
+	}()
+	root.WithNthChild(2, karytree.NewPersistent("c"))
+}
+
+func TestPersistentConcurrentReaders(t *testing.T) {
+	var current atomic.Pointer[karytree.Persistent[int]]
+	current.Store(karytree.NewPersistent(0))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				snapshot := current.Load()
+				sum := 0
+				for node := range snapshot.All() {
+					sum += node.Key()
+				}
+				if snapshot.Key() != sum {
+					t.Errorf("inconsistent snapshot: root %d, sum %d", snapshot.Key(), sum)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 200; i++ {
+		v := current.Load()
+		v = v.Update([]uint{uint(i % 7)}, func(node *karytree.Persistent[int]) *karytree.Persistent[int] {
+			if node == nil {
+				node = karytree.NewPersistent(0)
+			}
+			return node.WithNthChild(uint(i), karytree.NewPersistent(i))
+		})
+		current.Store(v.WithKey(v.Key() + i))
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,184 @@
+package karytree
+
+import (
+	"iter"
+	"sync"
+)
+
+// syncEntry is the key of a node in a SyncTree. Its lock guards the key
+// and the sibling list of the node's children.
+type syncEntry[T comparable] struct {
+	mu  sync.RWMutex
+	key T
+}
+
+// A SyncTree is a tree that is safe for concurrent use by multiple
+// goroutines. Nodes are addressed by their path from the root (see
+// Node.PathFromRoot).
+//
+// Each node has its own lock, which guards its key and its children.
+// Operations walk down from the root with hand-over-hand read locks,
+// holding at most two locks at a time, always parent before child, and
+// only lock the node they change for writing. Writers in different
+// subtrees don't block each other, and readers only block the writers of
+// the node they are reading.
+//
+// Traversals are weakly consistent: each node is seen as it was when it
+// was visited, so nodes that are inserted or removed concurrently may or
+// may not be seen.
+type SyncTree[T comparable] struct {
+	root *Node[*syncEntry[T]]
+}
+
+// NewSyncTree creates a SyncTree whose root has data key.
+func NewSyncTree[T comparable](key T) *SyncTree[T] {
+	root := NewNode(&syncEntry[T]{key: key})
+	return &SyncTree[T]{root: &root}
+}
+
+func lockNode[T comparable](node *Node[*syncEntry[T]], write bool) {
+	if write {
+		node.key.mu.Lock()
+	} else {
+		node.key.mu.RLock()
+	}
+}
+
+func unlockNode[T comparable](node *Node[*syncEntry[T]], write bool) {
+	if write {
+		node.key.mu.Unlock()
+	} else {
+		node.key.mu.RUnlock()
+	}
+}
+
+// lockPath walks down to the node at path with hand-over-hand read
+// locks, and returns it locked, for writing if write is set. It returns
+// nil, with nothing locked, if path leads nowhere.
+func (s *SyncTree[T]) lockPath(path []uint, write bool) *Node[*syncEntry[T]] {
+	node := s.root
+	lockNode(node, write && len(path) == 0)
+	for i, n := range path {
+		child := node.NthChild(n)
+		if child == nil {
+			unlockNode(node, false)
+			return nil
+		}
+		lockNode(child, write && i == len(path)-1)
+		unlockNode(node, false)
+		node = child
+	}
+	return node
+}
+
+// Get gets the data stored in the node at path. It reports false if
+// there is no such node.
+func (s *SyncTree[T]) Get(path []uint) (T, bool) {
+	node := s.lockPath(path, false)
+	if node == nil {
+		var zero T
+		return zero, false
+	}
+	defer unlockNode(node, false)
+	return node.key.key, true
+}
+
+// SetKey modifies the data in the node at path. It reports false if
+// there is no such node.
+func (s *SyncTree[T]) SetKey(path []uint, key T) bool {
+	node := s.lockPath(path, true)
+	if node == nil {
+		return false
+	}
+	defer unlockNode(node, true)
+	node.key.key = key
+	return true
+}
+
+// SetNthChild sets the Nth child of the node at path to a new leaf with
+// data key. If an existing subtree is evicted, a copy of it is returned,
+// like in Node.SetNthChild. It reports false if there is no node at path.
+func (s *SyncTree[T]) SetNthChild(path []uint, n uint, key T) (*Node[T], bool) {
+	node := s.lockPath(path, true)
+	if node == nil {
+		return nil, false
+	}
+
+	child := NewNode(&syncEntry[T]{key: key})
+	evicted := node.SetNthChild(n, &child)
+	unlockNode(node, true)
+
+	return snapshot(evicted), true
+}
+
+// RemoveNthChild removes the Nth child of the node at path, and returns
+// a copy of its subtree. It returns nil if there is no such child.
+func (s *SyncTree[T]) RemoveNthChild(path []uint, n uint) *Node[T] {
+	node := s.lockPath(path, true)
+	if node == nil {
+		return nil
+	}
+
+	removed := node.RemoveNthChild(n)
+	unlockNode(node, true)
+
+	return snapshot(removed)
+}
+
+// snapshot copies the tree rooted at node into plain nodes, read-locking
+// one node at a time.
+func snapshot[T comparable](node *Node[*syncEntry[T]]) *Node[T] {
+	if node == nil {
+		return nil
+	}
+
+	node.key.mu.RLock()
+	ret := NewNode(node.key.key)
+	ret.n = node.n
+	children := []*Node[*syncEntry[T]]{}
+	for child := node.firstChild; child != nil; child = child.nextSibling {
+		children = append(children, child)
+	}
+	node.key.mu.RUnlock()
+
+	for _, child := range children {
+		ret.setNthChild(child.n, snapshot(child))
+	}
+	return &ret
+}
+
+// Snapshot returns a copy of the whole tree as plain nodes. Like All, it
+// is weakly consistent.
+func (s *SyncTree[T]) Snapshot() *Node[T] {
+	return snapshot(s.root)
+}
+
+// All returns an iterator over the tree in BFS order, yielding the path
+// and data of each node. A node is only locked while its data and the
+// list of its children are read, so the loop body may modify the tree.
+func (s *SyncTree[T]) All() iter.Seq2[[]uint, T] {
+	return func(yield func([]uint, T) bool) {
+		type item struct {
+			node *Node[*syncEntry[T]]
+			path []uint
+		}
+		queue := []item{{s.root, []uint{}}}
+		var curr item
+
+		for len(queue) > 0 {
+			curr, queue = queue[0], queue[1:]
+
+			curr.node.key.mu.RLock()
+			key := curr.node.key.key
+			for child := curr.node.firstChild; child != nil; child = child.nextSibling {
+				path := append(append(make([]uint, 0, len(curr.path)+1), curr.path...), child.n)
+				queue = append(queue, item{child, path})
+			}
+			curr.node.key.mu.RUnlock()
+
+			if !yield(curr.path, key) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package karytree_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func TestSyncTreeBasics(t *testing.T) {
+	tree := karytree.NewSyncTree("a")
+
+	if _, ok := tree.SetNthChild([]uint{}, 4, "b"); !ok {
+		t.Fatalf("expected to insert at the root")
+	}
+	tree.SetNthChild([]uint{4}, 1, "c")
+	tree.SetNthChild([]uint{4, 1}, 0, "d")
+	if _, ok := tree.SetNthChild([]uint{7}, 0, "x"); ok {
+		t.Errorf("expected inserting under a missing node to fail")
+	}
+
+	if key, ok := tree.Get([]uint{4, 1, 0}); !ok || key != "d" {
+		t.Errorf("expected d, got %v, %v", key, ok)
+	}
+	if _, ok := tree.Get([]uint{4, 2}); ok {
+		t.Errorf("expected a missing node not to be found")
+	}
+	if !tree.SetKey([]uint{4, 1}, "C") || tree.SetKey([]uint{5}, "x") {
+		t.Errorf("SetKey didn't report whether the node exists")
+	}
+
+	evicted, _ := tree.SetNthChild([]uint{4}, 1, "e")
+	if evicted.String() != "(C d)" || evicted.ChildIndex() != 1 {
+		t.Errorf("expected the evicted subtree (C d) at 1, got %v", evicted)
+	}
+
+	if snapshot := tree.Snapshot(); snapshot.String() != "(a [4](b [1]e))" {
+		t.Errorf("expected (a [4](b [1]e)), got %v", snapshot)
+	}
+
+	paths := [][]uint{}
+	for path := range tree.All() {
+		paths = append(paths, path)
+	}
+	if len(paths) != 3 || len(paths[2]) != 2 || paths[2][0] != 4 || paths[2][1] != 1 {
+		t.Errorf("unexpected BFS paths %v", paths)
+	}
+
+	if removed := tree.RemoveNthChild([]uint{}, 4); removed.String() != "(b [1]e)" {
+		t.Errorf("expected to remove (b [1]e), got %v", removed)
+	}
+	if tree.RemoveNthChild([]uint{}, 4) != nil {
+		t.Errorf("expected nothing left to remove")
+	}
+}
+
+// TestSyncTreeConcurrentTraversals runs BFS traversals concurrently with
+// inserts and evictions; run it with -race.
+func TestSyncTreeConcurrentTraversals(t *testing.T) {
+	tree := karytree.NewSyncTree(0)
+	for i := uint(0); i < 4; i++ {
+		tree.SetNthChild([]uint{}, i, int(i))
+	}
+
+	var wg sync.WaitGroup
+	for w := uint(0); w < 4; w++ {
+		wg.Add(1)
+		go func(w uint) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				n := uint(i % 8)
+				// inserts evict the previous subtree at n half of the time
+				tree.SetNthChild([]uint{w}, n, i)
+				tree.SetNthChild([]uint{w, n}, uint(i%3), i)
+				if i%5 == 0 {
+					tree.RemoveNthChild([]uint{w}, uint(i%8))
+				}
+				tree.SetKey([]uint{w}, i)
+			}
+		}(w)
+	}
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				for path := range tree.All() {
+					if len(path) > 3 {
+						t.Errorf("unexpected path %v", path)
+					}
+				}
+				tree.Snapshot()
+				tree.Get([]uint{uint(i % 4), uint(i % 8)})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	snapshot := tree.Snapshot()
+	if snapshot.NumChildren() != 4 {
+		t.Errorf("expected the root to keep its 4 children, got %v", snapshot)
+	}
+	for _, child := range snapshot.Children() {
+		if child.Key() != 199 {
+			t.Errorf("expected the last SetKey to win, got %v", child.Key())
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package karytree
+
+import (
+	"iter"
+)
+
+// The traversals in binary-tree.go only follow Left() and Right(). The
+// ones below walk the whole sibling list, so they visit every child of
+// a k-ary node regardless of k.
+
+// KaryPreorderRecursive is a recursive preorder traversal with visitors
+// that visits every child of every node.
+func KaryPreorderRecursive[T comparable](root *Node[T], f func(*Node[T])) {
+	karyPreorder(root, visitAll(f))
+}
+
+// KaryPostorderRecursive is a recursive postorder traversal with visitors
+// that visits every child of every node.
+func KaryPostorderRecursive[T comparable](root *Node[T], f func(*Node[T])) {
+	karyPostorder(root, visitAll(f))
+}
+
+// KaryInorderRecursive is a recursive inorder traversal with visitors
+// for k-ary trees. Each node is visited after the subtrees of its
+// children with index n < pos, and before the rest. A pos of 1 on a
+// binary tree is the usual inorder traversal.
+func KaryInorderRecursive[T comparable](root *Node[T], pos uint, f func(*Node[T])) {
+	karyInorder(root, pos, visitAll(f))
+}
+
+// KaryPreorderSeq is an iterator-based preorder traversal that visits
+// every child of every node.
+func KaryPreorderSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		karyPreorder(root, yield)
+	}
+}
+
+// KaryPostorderSeq is an iterator-based postorder traversal that visits
+// every child of every node.
+func KaryPostorderSeq[T comparable](root *Node[T]) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		karyPostorder(root, yield)
+	}
+}
+
+// KaryInorderSeq is an iterator-based inorder traversal for k-ary trees,
+// with the same in-order position pos as KaryInorderRecursive.
+func KaryInorderSeq[T comparable](root *Node[T], pos uint) iter.Seq[*Node[T]] {
+	return func(yield func(*Node[T]) bool) {
+		karyInorder(root, pos, yield)
+	}
+}
+
+// Levels is an iterator-based BFS that yields the nodes of the tree one
+// level at a time, along with the depth of that level.
+func Levels[T comparable](root *Node[T]) iter.Seq2[int, []*Node[T]] {
+	return func(yield func(int, []*Node[T]) bool) {
+		if root == nil {
+			return
+		}
+
+		level := []*Node[T]{root}
+		for depth := 0; len(level) > 0; depth++ {
+			if !yield(depth, level) {
+				return
+			}
+
+			next := []*Node[T]{}
+			for _, curr := range level {
+				for child := curr.firstChild; child != nil; child = child.nextSibling {
+					next = append(next, child)
+				}
+			}
+			level = next
+		}
+	}
+}
+
+// VisitLevels is a BFS with visitors that calls f once per level of the
+// tree, with the depth of that level and its nodes.
+func VisitLevels[T comparable](root *Node[T], f func(int, []*Node[T])) {
+	for depth, level := range Levels(root) {
+		f(depth, level)
+	}
+}
+
+func visitAll[T comparable](f func(*Node[T])) func(*Node[T]) bool {
+	return func(node *Node[T]) bool {
+		f(node)
+		return true
+	}
+}
+
+// karyPreorder, karyPostorder and karyInorder return false as soon as
+// visit does, so that iterators can stop early.
+
+func karyPreorder[T comparable](root *Node[T], visit func(*Node[T]) bool) bool {
+	if root == nil {
+		return true
+	}
+	if !visit(root) {
+		return false
+	}
+	for child := root.firstChild; child != nil; child = child.nextSibling {
+		if !karyPreorder(child, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+func karyPostorder[T comparable](root *Node[T], visit func(*Node[T]) bool) bool {
+	if root == nil {
+		return true
+	}
+	for child := root.firstChild; child != nil; child = child.nextSibling {
+		if !karyPostorder(child, visit) {
+			return false
+		}
+	}
+	return visit(root)
+}
+
+func karyInorder[T comparable](root *Node[T], pos uint, visit func(*Node[T]) bool) bool {
+	if root == nil {
+		return true
+	}
+	child := root.firstChild
+	for ; child != nil && child.n < pos; child = child.nextSibling {
+		if !karyInorder(child, pos, visit) {
+			return false
+		}
+	}
+	if !visit(root) {
+		return false
+	}
+	for ; child != nil; child = child.nextSibling {
+		if !karyInorder(child, pos, visit) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package karytree_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+func constructTernaryTree() *karytree.Node[string] {
+	a := karytree.NewNode("a")
+	b := karytree.NewNode("b")
+	c := karytree.NewNode("c")
+	d := karytree.NewNode("d")
+	e := karytree.NewNode("e")
+	f := karytree.NewNode("f")
+	g := karytree.NewNode("g")
+	h := karytree.NewNode("h")
+
+	a.SetNthChild(0, &b)
+	a.SetNthChild(1, &c)
+	a.SetNthChild(2, &d)
+	b.SetNthChild(0, &e)
+	b.SetNthChild(1, &f)
+	b.SetNthChild(2, &g)
+	d.SetNthChild(2, &h)
+
+	/*
+	         a
+	      /  |  \
+	     b   c   d
+	    /|\       \
+	   e f g       h
+	*/
+
+	return &a
+}
+
+func keysOf(nodes []*karytree.Node[string]) []string {
+	keys := []string{}
+	for _, node := range nodes {
+		keys = append(keys, node.Key())
+	}
+	return keys
+}
+
+func TestKaryPreorder(t *testing.T) {
+	a := constructTernaryTree()
+	expected := []string{"a", "b", "e", "f", "g", "c", "d", "h"}
+
+	nodes := []*karytree.Node[string]{}
+	karytree.KaryPreorderRecursive(a, func(node *karytree.Node[string]) {
+		nodes = append(nodes, node)
+	})
+	if got := keysOf(nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected preorder %+v, got %+v", expected, got)
+	}
+
+	nodes = nodes[:0]
+	for node := range karytree.KaryPreorderSeq(a) {
+		nodes = append(nodes, node)
+	}
+	if got := keysOf(nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected preorder %+v, got %+v", expected, got)
+	}
+}
+
+func TestKaryPostorder(t *testing.T) {
+	a := constructTernaryTree()
+	expected := []string{"e", "f", "g", "b", "c", "h", "d", "a"}
+
+	nodes := []*karytree.Node[string]{}
+	karytree.KaryPostorderRecursive(a, func(node *karytree.Node[string]) {
+		nodes = append(nodes, node)
+	})
+	if got := keysOf(nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected postorder %+v, got %+v", expected, got)
+	}
+
+	nodes = nodes[:0]
+	for node := range karytree.KaryPostorderSeq(a) {
+		nodes = append(nodes, node)
+	}
+	if got := keysOf(nodes); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected postorder %+v, got %+v", expected, got)
+	}
+}
+
+func TestKaryInorder(t *testing.T) {
+	a := constructTernaryTree()
+
+	expected := map[uint][]string{
+		0: {"a", "b", "e", "f", "g", "c", "d", "h"},
+		1: {"e", "b", "f", "g", "a", "c", "d", "h"},
+		2: {"e", "f", "b", "g", "c", "a", "d", "h"},
+		3: {"e", "f", "g", "b", "c", "h", "d", "a"},
+	}
+
+	for pos, exp := range expected {
+		nodes := []*karytree.Node[string]{}
+		karytree.KaryInorderRecursive(a, pos, func(node *karytree.Node[string]) {
+			nodes = append(nodes, node)
+		})
+		if got := keysOf(nodes); !reflect.DeepEqual(got, exp) {
+			t.Errorf("pos %d: expected inorder %+v, got %+v", pos, exp, got)
+		}
+
+		nodes = nodes[:0]
+		for node := range karytree.KaryInorderSeq(a, pos) {
+			nodes = append(nodes, node)
+		}
+		if got := keysOf(nodes); !reflect.DeepEqual(got, exp) {
+			t.Errorf("pos %d: expected inorder %+v, got %+v", pos, exp, got)
+		}
+	}
+}
+
+func TestKaryInorderMatchesBinary(t *testing.T) {
+	a := karytree.Binary("a")
+	b := karytree.Binary("b")
+	c := karytree.Binary("c")
+	d := karytree.Binary("d")
+
+	a.SetRight(&b)
+	b.SetLeft(&c)
+	c.SetRight(&d)
+
+	expected := []*karytree.Node[string]{}
+	for node := range karytree.InorderSeq(&a) {
+		expected = append(expected, node)
+	}
+
+	got := []*karytree.Node[string]{}
+	for node := range karytree.KaryInorderSeq(&a, 1) {
+		got = append(got, node)
+	}
+
+	if !reflect.DeepEqual(keysOf(got), keysOf(expected)) {
+		t.Errorf("expected k-ary inorder with pos 1 to match binary inorder %+v, got %+v", keysOf(expected), keysOf(got))
+	}
+}
+
+func TestKarySeqEarlyBreak(t *testing.T) {
+	a := constructTernaryTree()
+
+	ctr := 0
+	for node := range karytree.KaryPostorderSeq(a) {
+		ctr++
+		if node.Key() == "b" {
+			break
+		}
+	}
+	if ctr != 4 {
+		t.Errorf("expected postorder to stop at b after 4 nodes, got %d", ctr)
+	}
+
+	ctr = 0
+	for node := range karytree.KaryInorderSeq(a, 1) {
+		ctr++
+		if node.Key() == "a" {
+			break
+		}
+	}
+	if ctr != 5 {
+		t.Errorf("expected inorder to stop at a after 5 nodes, got %d", ctr)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	a := constructTernaryTree()
+	expected := [][]string{{"a"}, {"b", "c", "d"}, {"e", "f", "g", "h"}}
+
+	got := [][]string{}
+	for depth, level := range karytree.Levels(a) {
+		if depth != len(got) {
+			t.Errorf("expected depth %d, got %d", len(got), depth)
+		}
+		got = append(got, keysOf(level))
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected levels %+v, got %+v", expected, got)
+	}
+
+	got = [][]string{}
+	karytree.VisitLevels(a, func(depth int, level []*karytree.Node[string]) {
+		got = append(got, keysOf(level))
+	})
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected levels %+v, got %+v", expected, got)
+	}
+
+	for range karytree.Levels[string](nil) {
+		t.Errorf("expected no levels from a nil root")
+	}
+}
@@ -0,0 +1,81 @@
+package karytree
+
+// An Action tells Walk how to proceed after visiting a node.
+type Action int
+
+const (
+	// Continue walks into the children of the node.
+	Continue Action = iota
+	// SkipChildren prunes the subtree below the node.
+	SkipChildren
+	// Stop aborts the whole walk.
+	Stop
+)
+
+// A Visitor's Visit method is called by Walk when it enters each node,
+// with the depth of the node relative to the root of the walk. The
+// child index of the node is available from node.ChildIndex().
+type Visitor[T comparable] interface {
+	Visit(node *Node[T], depth int) Action
+}
+
+// A LeaveVisitor is a Visitor that is also told when Walk leaves a node,
+// after its children have been walked. Leave is called for every node
+// whose Visit didn't return Stop, including those that returned
+// SkipChildren. Only a Stop returned from Leave has an effect.
+type LeaveVisitor[T comparable] interface {
+	Visitor[T]
+	Leave(node *Node[T], depth int) Action
+}
+
+// VisitorFunc adapts an ordinary function to a Visitor.
+type VisitorFunc[T comparable] func(node *Node[T], depth int) Action
+
+// Visit calls f(node, depth).
+func (f VisitorFunc[T]) Visit(node *Node[T], depth int) Action {
+	return f(node, depth)
+}
+
+// Walk traverses the tree rooted at root in depth-first order, following
+// the whole sibling list of each node. v.Visit is called when entering
+// each node, and its return value decides whether Walk descends into the
+// node's children, skips them, or stops. If v is a LeaveVisitor, its
+// Leave method is called when leaving each node.
+func Walk[T comparable](root *Node[T], v Visitor[T]) {
+	lv, _ := v.(LeaveVisitor[T])
+	walk(root, 0, v, lv)
+}
+
+func walk[T comparable](node *Node[T], depth int, v Visitor[T], lv LeaveVisitor[T]) bool {
+	if node == nil {
+		return true
+	}
+
+	switch v.Visit(node, depth) {
+	case Stop:
+		return false
+	case Continue:
+		for child := node.firstChild; child != nil; child = child.nextSibling {
+			if !walk(child, depth+1, v, lv) {
+				return false
+			}
+		}
+	}
+
+	if lv != nil && lv.Leave(node, depth) == Stop {
+		return false
+	}
+	return true
+}
+
+// Inspect traverses the tree rooted at root in depth-first order, like
+// go/ast.Inspect. It calls f(node) for each node; if f returns false,
+// the children of that node are skipped.
+func Inspect[T comparable](root *Node[T], f func(*Node[T]) bool) {
+	Walk[T](root, VisitorFunc[T](func(node *Node[T], depth int) Action {
+		if f(node) {
+			return Continue
+		}
+		return SkipChildren
+	}))
+}
@@ -0,0 +1,103 @@
+package karytree_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/sevagh/k-ary-tree"
+)
+
+type recordingVisitor struct {
+	events []string
+	skip   string
+	stop   string
+}
+
+func (v *recordingVisitor) Visit(node *karytree.Node[string], depth int) karytree.Action {
+	v.events = append(v.events, fmt.Sprintf("enter %s %d [%d]", node.Key(), depth, node.ChildIndex()))
+	switch node.Key() {
+	case v.skip:
+		return karytree.SkipChildren
+	case v.stop:
+		return karytree.Stop
+	}
+	return karytree.Continue
+}
+
+func (v *recordingVisitor) Leave(node *karytree.Node[string], depth int) karytree.Action {
+	v.events = append(v.events, fmt.Sprintf("leave %s %d", node.Key(), depth))
+	return karytree.Continue
+}
+
+func TestWalkEnterLeave(t *testing.T) {
+	a := constructTernaryTree()
+
+	v := &recordingVisitor{skip: "b"}
+	karytree.Walk[string](a, v)
+
+	expected := []string{
+		"enter a 0 [0]",
+		"enter b 1 [0]",
+		"leave b 1",
+		"enter c 1 [1]",
+		"leave c 1",
+		"enter d 1 [2]",
+		"enter h 2 [2]",
+		"leave h 2",
+		"leave d 1",
+		"leave a 0",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, v.events)
+	}
+}
+
+func TestWalkStop(t *testing.T) {
+	a := constructTernaryTree()
+
+	v := &recordingVisitor{stop: "f"}
+	karytree.Walk[string](a, v)
+
+	expected := []string{
+		"enter a 0 [0]",
+		"enter b 1 [0]",
+		"enter e 2 [0]",
+		"leave e 2",
+		"enter f 2 [1]",
+	}
+	if !reflect.DeepEqual(v.events, expected) {
+		t.Errorf("expected events %+v, got %+v", expected, v.events)
+	}
+}
+
+func TestWalkVisitorFunc(t *testing.T) {
+	a := constructTernaryTree()
+
+	keys := []string{}
+	karytree.Walk[string](a, karytree.VisitorFunc[string](func(node *karytree.Node[string], depth int) karytree.Action {
+		keys = append(keys, node.Key())
+		if depth == 1 {
+			return karytree.SkipChildren
+		}
+		return karytree.Continue
+	}))
+
+	if expected := []string{"a", "b", "c", "d"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %+v, got %+v", expected, keys)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	a := constructTernaryTree()
+
+	keys := []string{}
+	karytree.Inspect(a, func(node *karytree.Node[string]) bool {
+		keys = append(keys, node.Key())
+		return node.Key() != "d"
+	})
+
+	if expected := []string{"a", "b", "e", "f", "g", "c", "d"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected %+v, got %+v", expected, keys)
+	}
+}